@@ -0,0 +1,207 @@
+package apigee
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zambien/go-apigee-edge"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	flavorEdge = "edge"
+	flavorX    = "x"
+
+	defaultApigeeXBaseURI = "https://apigee.googleapis.com"
+	apigeeXScope          = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// isApigeeX reports whether the client talks to the Apigee X / hybrid management API rather than Edge.
+func isApigeeX(client *apigee.EdgeClient) bool {
+	_, ok := client.Proxies.(*xProxiesService)
+	return ok
+}
+
+// useApigeeX points an Edge client at the Apigee X management API.  go-apigee-edge builds every request from BaseURL
+// so only the services whose requests or responses differ on X are replaced.
+func useApigeeX(client *apigee.EdgeClient, baseURI string, org string) error {
+
+	if baseURI == "" {
+		baseURI = defaultApigeeXBaseURI
+	}
+	baseURL, err := url.Parse(baseURI)
+	if err != nil {
+		return err
+	}
+	baseURL.Path = path.Join(baseURL.Path, "v1/organizations", org)
+	client.BaseURL = baseURL
+
+	client.Proxies = &xProxiesService{bundles: xBundleService{client: client, resourcePath: proxiesPath}}
+	client.SharedFlows = &xSharedFlowsService{bundles: xBundleService{client: client, resourcePath: sharedFlowsPath}}
+	client.DeveloperApps = &xDeveloperAppsService{client: client}
+	client.TargetServers = &xTargetServersService{client: client}
+	client.Companies = &xCompaniesService{}
+	client.CompanyApps = &xCompanyAppsService{}
+
+	return nil
+}
+
+// googleTokenSource authorizes requests with a Google OAuth2 access token for a service account, or for the
+// application default credentials when no service account key is given.
+type googleTokenSource struct {
+	newSource func() oauth2.TokenSource
+
+	mu     sync.Mutex
+	source oauth2.TokenSource
+}
+
+func newGoogleTokenSource(credentials string, transport http.RoundTripper) (*googleTokenSource, error) {
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport, Timeout: 60 * time.Second})
+
+	var creds *google.Credentials
+	var err error
+	if credentials == "" {
+		creds, err = google.FindDefaultCredentials(ctx, apigeeXScope)
+	} else {
+		contents, readErr := readCredentials(credentials)
+		if readErr != nil {
+			return nil, readErr
+		}
+		creds, err = google.CredentialsFromJSON(ctx, contents, apigeeXScope)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] newGoogleTokenSource error loading credentials: %s", err.Error())
+	}
+
+	s := &googleTokenSource{
+		newSource: func() oauth2.TokenSource { return oauth2.ReuseTokenSource(nil, creds.TokenSource) },
+	}
+	s.source = s.newSource()
+
+	return s, nil
+}
+
+// readCredentials accepts either the path to a service account key file or its JSON contents.
+func readCredentials(credentials string) ([]byte, error) {
+
+	if strings.HasPrefix(strings.TrimSpace(credentials), "{") {
+		return []byte(credentials), nil
+	}
+
+	contents, err := ioutil.ReadFile(credentials)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] readCredentials error reading %s: %s", credentials, err.Error())
+	}
+
+	return contents, nil
+}
+
+// Token returns a valid access token.  oauth2 fetches tokens without a context of its own, the wait is bounded by
+// ctx and a token that arrives later is cached for the next request.
+func (s *googleTokenSource) Token(ctx context.Context) (string, error) {
+
+	s.mu.Lock()
+	source := s.source
+	s.mu.Unlock()
+
+	type result struct {
+		token *oauth2.Token
+		err   error
+	}
+	fetched := make(chan result, 1)
+	go func() {
+		token, err := source.Token()
+		fetched <- result{token, err}
+	}()
+
+	select {
+	case r := <-fetched:
+		if r.err != nil {
+			return "", fmt.Errorf("[ERROR] googleTokenSource error requesting token: %s", r.err.Error())
+		}
+		return r.token.AccessToken, nil
+	case <-ctx.Done():
+		return "", fmt.Errorf("[ERROR] googleTokenSource error requesting token: %s", ctx.Err())
+	}
+}
+
+func (s *googleTokenSource) Invalidate(accessToken string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token, err := s.source.Token(); err == nil && token.AccessToken == accessToken {
+		s.source = s.newSource()
+	}
+}
+
+// xTimestamp decodes the epoch milliseconds Apigee X sends as strings.
+type xTimestamp struct {
+	apigee.Timestamp
+}
+
+func (t *xTimestamp) UnmarshalJSON(b []byte) error {
+	return t.Timestamp.UnmarshalJSON([]byte(strings.Trim(string(b), `"`)))
+}
+
+// xInt decodes the int64 values Apigee X sends as strings.
+type xInt int
+
+func (i *xInt) UnmarshalJSON(b []byte) error {
+
+	value, err := strconv.ParseInt(strings.Trim(string(b), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = xInt(value)
+
+	return nil
+}
+
+// xBool decodes booleans whether Apigee sends them as JSON booleans or as strings.
+type xBool bool
+
+func (b *xBool) UnmarshalJSON(data []byte) error {
+
+	value, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*b = xBool(value)
+
+	return nil
+}
+
+// xDo sends a request to the management API and decodes the JSON response into v.
+func xDo(client *apigee.EdgeClient, method string, requestPath string, query url.Values, body interface{}, v interface{}) (*apigee.Response, error) {
+
+	requestURL, err := url.Parse(requestPath)
+	if err != nil {
+		return nil, err
+	}
+	if query != nil {
+		requestURL.RawQuery = query.Encode()
+	}
+
+	req, err := client.NewRequest(method, requestURL.String(), body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(req, v)
+}
+
+// unsupportedOnApigeeX is returned by every call to an Edge API that has no Apigee X counterpart.
+func unsupportedOnApigeeX(what string, instead string) error {
+	return fmt.Errorf("[ERROR] %s are not supported on Apigee X, use %s instead", what, instead)
+}
@@ -0,0 +1,698 @@
+package apigee
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zambien/go-apigee-edge"
+)
+
+// Apigee X representations of proxies and shared flows.  Both are bundles with revisions and share one API.
+type xBundle struct {
+	Name      string            `json:"name,omitempty"`
+	Revisions []apigee.Revision `json:"revision,omitempty"`
+	MetaData  struct {
+		CreatedAt      xTimestamp `json:"createdAt,omitempty"`
+		LastModifiedAt xTimestamp `json:"lastModifiedAt,omitempty"`
+	} `json:"metaData,omitempty"`
+}
+
+type xBundleRevision struct {
+	Name            string          `json:"name,omitempty"`
+	Revision        apigee.Revision `json:"revision,omitempty"`
+	DisplayName     string          `json:"displayName,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	CreatedAt       xTimestamp      `json:"createdAt,omitempty"`
+	LastModifiedAt  xTimestamp      `json:"lastModifiedAt,omitempty"`
+	Policies        []string        `json:"policies,omitempty"`
+	ProxyEndpoints  []string        `json:"proxyEndpoints,omitempty"`
+	TargetEndpoints []string        `json:"targetEndpoints,omitempty"`
+	TargetServers   []string        `json:"targetServers,omitempty"`
+	Resources       []string        `json:"resources,omitempty"`
+	Type            string          `json:"type,omitempty"`
+}
+
+type xDeployment struct {
+	Environment string          `json:"environment,omitempty"`
+	ApiProxy    string          `json:"apiProxy,omitempty"`
+	Revision    apigee.Revision `json:"revision,omitempty"`
+	State       string          `json:"state,omitempty"`
+}
+
+// xDeploymentState returns the Edge name of an Apigee X deployment state, READY is what Edge calls deployed.
+func xDeploymentState(state string) string {
+
+	switch state {
+	case "READY":
+		return "deployed"
+	case "PROGRESSING":
+		return "pending"
+	}
+
+	return strings.ToLower(state)
+}
+
+type xBundleService struct {
+	client       *apigee.EdgeClient
+	resourcePath string
+}
+
+func (s *xBundleService) list() ([]string, *apigee.Response, error) {
+
+	listed := map[string][]struct {
+		Name string `json:"name"`
+	}{}
+	resp, err := xDo(s.client, "GET", s.resourcePath, nil, nil, &listed)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	names := []string{}
+	for _, bundles := range listed {
+		for _, bundle := range bundles {
+			names = append(names, bundle.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, resp, nil
+}
+
+func (s *xBundleService) get(name string) (*xBundle, *apigee.Response, error) {
+
+	bundle := xBundle{}
+	resp, err := xDo(s.client, "GET", path.Join(s.resourcePath, name), nil, nil, &bundle)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &bundle, resp, nil
+}
+
+// importBundle uploads a zip bundle as a new revision.  Apigee X only takes bundles as multipart form data.
+func (s *xBundleService) importBundle(name string, source string) (*xBundleRevision, *apigee.Response, error) {
+
+	if !strings.HasSuffix(source, ".zip") {
+		return nil, nil, fmt.Errorf("[ERROR] bundle %s must be a zip file on Apigee X", source)
+	}
+	bundle, err := os.Open(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer bundle.Close()
+
+	body := new(bytes.Buffer)
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", filepath.Base(source))
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.Copy(part, bundle); err != nil {
+		return nil, nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	importURL, err := url.Parse(s.resourcePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	importURL.RawQuery = url.Values{"action": {"import"}, "name": {name}}.Encode()
+
+	req, err := s.client.NewRequest("POST", importURL.String(), bytes.NewReader(body.Bytes()), form.FormDataContentType())
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	revision := xBundleRevision{}
+	resp, err := s.client.Do(req, &revision)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &revision, resp, nil
+}
+
+func (s *xBundleService) delete(name string) (*apigee.Response, error) {
+	return xDo(s.client, "DELETE", path.Join(s.resourcePath, name), nil, nil, nil)
+}
+
+func (s *xBundleService) deleteRevision(name string, rev apigee.Revision) (*xBundleRevision, *apigee.Response, error) {
+
+	revision := xBundleRevision{}
+	resp, err := xDo(s.client, "DELETE", path.Join(s.resourcePath, name, "revisions", rev.String()), nil, nil, &revision)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &revision, resp, nil
+}
+
+// deploy deploys a revision.  Apigee X has no delay, with override the previous revision is replaced seamlessly.
+func (s *xBundleService) deploy(name string, env string, rev apigee.Revision, override bool) (*xDeployment, *apigee.Response, error) {
+
+	deploymentPath := path.Join("environments", env, s.resourcePath, name, "revisions", rev.String(), "deployments")
+	deployment := xDeployment{}
+	resp, err := xDo(s.client, "POST", deploymentPath, url.Values{"override": {strconv.FormatBool(override)}}, nil, &deployment)
+	if err != nil {
+		return nil, resp, err
+	}
+	deployment.Environment = env
+	deployment.Revision = rev
+
+	return &deployment, resp, nil
+}
+
+func (s *xBundleService) undeploy(name string, env string, rev apigee.Revision) (*apigee.Response, error) {
+
+	deploymentPath := path.Join("environments", env, s.resourcePath, name, "revisions", rev.String(), "deployments")
+	return xDo(s.client, "DELETE", deploymentPath, nil, nil, nil)
+}
+
+// deployments lists where the bundle is deployed in the shape Edge reports it.
+func (s *xBundleService) deployments(name string) ([]apigee.EnvironmentDeployment, *apigee.Response, error) {
+
+	listed := struct {
+		Deployments []xDeployment `json:"deployments"`
+	}{}
+	resp, err := xDo(s.client, "GET", path.Join(s.resourcePath, name, "deployments"), nil, nil, &listed)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	environments := []apigee.EnvironmentDeployment{}
+	index := map[string]int{}
+	for _, deployment := range listed.Deployments {
+		i, ok := index[deployment.Environment]
+		if !ok {
+			i = len(environments)
+			index[deployment.Environment] = i
+			environments = append(environments, apigee.EnvironmentDeployment{Name: deployment.Environment})
+		}
+		environments[i].Revision = append(environments[i].Revision, apigee.RevisionDeployment{
+			Number: deployment.Revision,
+			State:  xDeploymentState(deployment.State),
+		})
+	}
+
+	return environments, resp, nil
+}
+
+type xProxiesService struct {
+	bundles xBundleService
+}
+
+var _ apigee.ProxiesService = &xProxiesService{}
+
+func (s *xProxiesService) List() ([]string, *apigee.Response, error) {
+	return s.bundles.list()
+}
+
+func (s *xProxiesService) Get(name string) (*apigee.Proxy, *apigee.Response, error) {
+
+	bundle, resp, err := s.bundles.get(name)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.Proxy{
+		Name:      bundle.Name,
+		Revisions: bundle.Revisions,
+		MetaData: apigee.ProxyMetadata{
+			CreatedAt:      bundle.MetaData.CreatedAt.Timestamp,
+			LastModifiedAt: bundle.MetaData.LastModifiedAt.Timestamp,
+		},
+	}, resp, nil
+}
+
+func (s *xProxiesService) Import(name string, source string) (*apigee.ProxyRevision, *apigee.Response, error) {
+
+	revision, resp, err := s.bundles.importBundle(name, source)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return revision.proxyRevision(), resp, nil
+}
+
+func (s *xProxiesService) Delete(name string) (*apigee.DeletedProxyInfo, *apigee.Response, error) {
+
+	resp, err := s.bundles.delete(name)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.DeletedProxyInfo{Name: name}, resp, nil
+}
+
+func (s *xProxiesService) DeleteRevision(name string, rev apigee.Revision) (*apigee.ProxyRevision, *apigee.Response, error) {
+
+	revision, resp, err := s.bundles.deleteRevision(name, rev)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return revision.proxyRevision(), resp, nil
+}
+
+func (s *xProxiesService) Deploy(name string, env string, rev apigee.Revision, delay int, override bool) (*apigee.ProxyRevisionDeployment, *apigee.Response, error) {
+
+	deployment, resp, err := s.bundles.deploy(name, env, rev, override)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return deployment.proxyRevisionDeployment(name, s.bundles.client), resp, nil
+}
+
+func (s *xProxiesService) ReDeploy(name string, env string, rev apigee.Revision, delay int, override bool) (*apigee.ProxyRevisionDeployments, *apigee.Response, error) {
+
+	deployment, resp, err := s.bundles.deploy(name, env, rev, override)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.ProxyRevisionDeployments{
+		Name:         name,
+		Organization: path.Base(s.bundles.client.BaseURL.Path),
+		Environments: []apigee.ProxyRevisionDeployment{*deployment.proxyRevisionDeployment(name, s.bundles.client)},
+	}, resp, nil
+}
+
+func (s *xProxiesService) Undeploy(name string, env string, rev apigee.Revision) (*apigee.ProxyRevisionDeployment, *apigee.Response, error) {
+
+	resp, err := s.bundles.undeploy(name, env, rev)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.ProxyRevisionDeployment{Name: name, Environment: env, Revision: rev}, resp, nil
+}
+
+func (s *xProxiesService) Export(name string, rev apigee.Revision) (string, *apigee.Response, error) {
+
+	bundle, err := exportRevision(s.bundles.client, proxiesPath, name, rev)
+	if err != nil {
+		return "", nil, err
+	}
+
+	filename := fmt.Sprintf("%s-r%d.zip", name, rev)
+	if err := ioutil.WriteFile(filename, bundle, 0644); err != nil {
+		return "", nil, err
+	}
+
+	return filename, nil, nil
+}
+
+func (s *xProxiesService) GetDeployments(name string) (*apigee.ProxyDeployment, *apigee.Response, error) {
+
+	environments, resp, err := s.bundles.deployments(name)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.ProxyDeployment{
+		Name:         name,
+		Organization: path.Base(s.bundles.client.BaseURL.Path),
+		Environments: environments,
+	}, resp, nil
+}
+
+func (r *xBundleRevision) proxyRevision() *apigee.ProxyRevision {
+	return &apigee.ProxyRevision{
+		Name:            r.Name,
+		Revision:        r.Revision,
+		DisplayName:     r.DisplayName,
+		Description:     r.Description,
+		CreatedAt:       r.CreatedAt.Timestamp,
+		LastModifiedAt:  r.LastModifiedAt.Timestamp,
+		Policies:        r.Policies,
+		ProxyEndpoints:  r.ProxyEndpoints,
+		TargetEndpoints: r.TargetEndpoints,
+		TargetServers:   r.TargetServers,
+		Resources:       r.Resources,
+		Type:            r.Type,
+	}
+}
+
+func (d *xDeployment) proxyRevisionDeployment(name string, client *apigee.EdgeClient) *apigee.ProxyRevisionDeployment {
+	return &apigee.ProxyRevisionDeployment{
+		Name:         name,
+		Revision:     d.Revision,
+		Environment:  d.Environment,
+		Organization: path.Base(client.BaseURL.Path),
+		State:        xDeploymentState(d.State),
+	}
+}
+
+type xSharedFlowsService struct {
+	bundles xBundleService
+}
+
+var _ apigee.SharedFlowService = &xSharedFlowsService{}
+
+func (s *xSharedFlowsService) List() ([]string, *apigee.Response, error) {
+	return s.bundles.list()
+}
+
+func (s *xSharedFlowsService) Get(name string) (*apigee.SharedFlow, *apigee.Response, error) {
+
+	bundle, resp, err := s.bundles.get(name)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.SharedFlow{
+		Name:      bundle.Name,
+		Revisions: bundle.Revisions,
+		MetaData: apigee.SharedFlowMetadata{
+			CreatedAt:      bundle.MetaData.CreatedAt.Timestamp,
+			LastModifiedAt: bundle.MetaData.LastModifiedAt.Timestamp,
+		},
+	}, resp, nil
+}
+
+func (s *xSharedFlowsService) Import(name string, source string) (*apigee.SharedFlowRevision, *apigee.Response, error) {
+
+	revision, resp, err := s.bundles.importBundle(name, source)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.SharedFlowRevision{
+		Name:            revision.Name,
+		Revision:        revision.Revision,
+		DisplayName:     revision.DisplayName,
+		Description:     revision.Description,
+		CreatedAt:       revision.CreatedAt.Timestamp,
+		LastModifiedAt:  revision.LastModifiedAt.Timestamp,
+		Policies:        revision.Policies,
+		TargetEndpoints: revision.TargetEndpoints,
+		TargetServers:   revision.TargetServers,
+		Resources:       revision.Resources,
+		Type:            revision.Type,
+	}, resp, nil
+}
+
+func (s *xSharedFlowsService) Delete(name string) (*apigee.DeletedSharedFlowInfo, *apigee.Response, error) {
+
+	resp, err := s.bundles.delete(name)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.DeletedSharedFlowInfo{Name: name}, resp, nil
+}
+
+func (s *xSharedFlowsService) Deploy(name string, env string, rev apigee.Revision, delay int, override bool) (*apigee.SharedFlowRevisionDeployment, *apigee.Response, error) {
+
+	deployment, resp, err := s.bundles.deploy(name, env, rev, override)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return deployment.sharedFlowRevisionDeployment(name, s.bundles.client), resp, nil
+}
+
+func (s *xSharedFlowsService) ReDeploy(name string, env string, rev apigee.Revision, delay int, override bool) (*apigee.SharedFlowRevisionDeployments, *apigee.Response, error) {
+
+	deployment, resp, err := s.bundles.deploy(name, env, rev, override)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.SharedFlowRevisionDeployments{
+		Name:         name,
+		Organization: path.Base(s.bundles.client.BaseURL.Path),
+		Environments: []apigee.SharedFlowRevisionDeployment{*deployment.sharedFlowRevisionDeployment(name, s.bundles.client)},
+	}, resp, nil
+}
+
+func (s *xSharedFlowsService) Undeploy(name string, env string, rev apigee.Revision) (*apigee.SharedFlowRevisionDeployment, *apigee.Response, error) {
+
+	resp, err := s.bundles.undeploy(name, env, rev)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.SharedFlowRevisionDeployment{Name: name, Environment: env, Revision: rev}, resp, nil
+}
+
+func (s *xSharedFlowsService) GetDeployments(name string) (*apigee.SharedFlowDeployment, *apigee.Response, error) {
+
+	environments, resp, err := s.bundles.deployments(name)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &apigee.SharedFlowDeployment{
+		Name:         name,
+		Organization: path.Base(s.bundles.client.BaseURL.Path),
+		Environments: environments,
+	}, resp, nil
+}
+
+func (d *xDeployment) sharedFlowRevisionDeployment(name string, client *apigee.EdgeClient) *apigee.SharedFlowRevisionDeployment {
+	return &apigee.SharedFlowRevisionDeployment{
+		Name:         name,
+		Revision:     d.Revision,
+		Environment:  d.Environment,
+		Organization: path.Base(client.BaseURL.Path),
+		State:        xDeploymentState(d.State),
+	}
+}
+
+// Apigee X sends the credential timestamps as strings.
+type xCredential struct {
+	ApiProducts    []apigee.CredentialApiProduct `json:"apiProducts,omitempty"`
+	Attributes     []apigee.Attribute            `json:"attributes,omitempty"`
+	ConsumerKey    string                        `json:"consumerKey,omitempty"`
+	ConsumerSecret string                        `json:"consumerSecret,omitempty"`
+	ExpiresAt      xInt                          `json:"expiresAt,omitempty"`
+	IssuedAt       xInt                          `json:"issuedAt,omitempty"`
+	Scopes         []string                      `json:"scopes,omitempty"`
+	Status         string                        `json:"status,omitempty"`
+}
+
+type xDeveloperApp struct {
+	Name         string             `json:"name,omitempty"`
+	ApiProducts  []string           `json:"apiProducts,omitempty"`
+	KeyExpiresIn xInt               `json:"keyExpiresIn,omitempty"`
+	Attributes   []apigee.Attribute `json:"attributes,omitempty"`
+	Scopes       []string           `json:"scopes,omitempty"`
+	CallbackUrl  string             `json:"callbackUrl,omitempty"`
+	Credentials  []xCredential      `json:"credentials,omitempty"`
+	AppId        string             `json:"appId,omitempty"`
+	DeveloperId  string             `json:"developerId,omitempty"`
+	AppFamily    string             `json:"appFamily,omitempty"`
+	Status       string             `json:"status,omitempty"`
+}
+
+func (a *xDeveloperApp) developerApp() *apigee.DeveloperApp {
+
+	credentials := []apigee.Credential{}
+	for _, c := range a.Credentials {
+		credentials = append(credentials, apigee.Credential{
+			ApiProducts:    c.ApiProducts,
+			Attributes:     c.Attributes,
+			ConsumerKey:    c.ConsumerKey,
+			ConsumerSecret: c.ConsumerSecret,
+			ExpiresAt:      int(c.ExpiresAt),
+			IssuedAt:       int(c.IssuedAt),
+			Scopes:         c.Scopes,
+		})
+	}
+
+	return &apigee.DeveloperApp{
+		Name:         a.Name,
+		ApiProducts:  a.ApiProducts,
+		KeyExpiresIn: int(a.KeyExpiresIn),
+		Attributes:   a.Attributes,
+		Scopes:       a.Scopes,
+		CallbackUrl:  a.CallbackUrl,
+		Credentials:  credentials,
+		AppId:        a.AppId,
+		DeveloperId:  a.DeveloperId,
+		AppFamily:    a.AppFamily,
+		Status:       a.Status,
+	}
+}
+
+type xDeveloperAppsService struct {
+	client *apigee.EdgeClient
+}
+
+var _ apigee.DeveloperAppService = &xDeveloperAppsService{}
+
+func (s *xDeveloperAppsService) Get(email string, name string) (*apigee.DeveloperApp, *apigee.Response, error) {
+	return s.send("GET", path.Join("developers", email, "apps", name), nil)
+}
+
+func (s *xDeveloperAppsService) Create(email string, app apigee.DeveloperApp) (*apigee.DeveloperApp, *apigee.Response, error) {
+	return s.send("POST", path.Join("developers", email, "apps"), app)
+}
+
+func (s *xDeveloperAppsService) Update(email string, app apigee.DeveloperApp) (*apigee.DeveloperApp, *apigee.Response, error) {
+	return s.send("PUT", path.Join("developers", email, "apps", app.Name), app)
+}
+
+func (s *xDeveloperAppsService) Delete(email string, name string) (*apigee.Response, error) {
+	return xDo(s.client, "DELETE", path.Join("developers", email, "apps", name), nil, nil, nil)
+}
+
+func (s *xDeveloperAppsService) send(method string, appPath string, body interface{}) (*apigee.DeveloperApp, *apigee.Response, error) {
+
+	app := xDeveloperApp{}
+	resp, err := xDo(s.client, method, appPath, nil, body, &app)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return app.developerApp(), resp, nil
+}
+
+// Apigee X takes and returns the TLS settings of a target server as booleans where Edge uses strings.
+type xSSLInfo struct {
+	Enabled                xBool    `json:"enabled"`
+	ClientAuthEnabled      xBool    `json:"clientAuthEnabled"`
+	KeyStore               string   `json:"keyStore,omitempty"`
+	TrustStore             string   `json:"trustStore,omitempty"`
+	KeyAlias               string   `json:"keyAlias,omitempty"`
+	Ciphers                []string `json:"ciphers,omitempty"`
+	IgnoreValidationErrors bool     `json:"ignoreValidationErrors"`
+	Protocols              []string `json:"protocols,omitempty"`
+}
+
+type xTargetServer struct {
+	Name    string    `json:"name,omitempty"`
+	Host    string    `json:"host,omitempty"`
+	Enabled bool      `json:"isEnabled"`
+	Port    int       `json:"port,omitempty"`
+	SSLInfo *xSSLInfo `json:"sSLInfo,omitempty"`
+}
+
+func newXTargetServer(ts apigee.TargetServer) xTargetServer {
+
+	target := xTargetServer{Name: ts.Name, Host: ts.Host, Enabled: ts.Enabled, Port: ts.Port}
+	if ts.SSLInfo != nil {
+		enabled, _ := strconv.ParseBool(ts.SSLInfo.SSLEnabled)
+		clientAuthEnabled, _ := strconv.ParseBool(ts.SSLInfo.ClientAuthEnabled)
+		target.SSLInfo = &xSSLInfo{
+			Enabled:                xBool(enabled),
+			ClientAuthEnabled:      xBool(clientAuthEnabled),
+			KeyStore:               ts.SSLInfo.KeyStore,
+			TrustStore:             ts.SSLInfo.TrustStore,
+			KeyAlias:               ts.SSLInfo.KeyAlias,
+			Ciphers:                ts.SSLInfo.Ciphers,
+			IgnoreValidationErrors: ts.SSLInfo.IgnoreValidationErrors,
+			Protocols:              ts.SSLInfo.Protocols,
+		}
+	}
+
+	return target
+}
+
+func (t *xTargetServer) targetServer() *apigee.TargetServer {
+
+	ts := &apigee.TargetServer{Name: t.Name, Host: t.Host, Enabled: t.Enabled, Port: t.Port}
+	if t.SSLInfo != nil {
+		ts.SSLInfo = &apigee.SSLInfo{
+			SSLEnabled:             strconv.FormatBool(bool(t.SSLInfo.Enabled)),
+			ClientAuthEnabled:      strconv.FormatBool(bool(t.SSLInfo.ClientAuthEnabled)),
+			KeyStore:               t.SSLInfo.KeyStore,
+			TrustStore:             t.SSLInfo.TrustStore,
+			KeyAlias:               t.SSLInfo.KeyAlias,
+			Ciphers:                t.SSLInfo.Ciphers,
+			IgnoreValidationErrors: t.SSLInfo.IgnoreValidationErrors,
+			Protocols:              t.SSLInfo.Protocols,
+		}
+	}
+
+	return ts
+}
+
+type xTargetServersService struct {
+	client *apigee.EdgeClient
+}
+
+var _ apigee.TargetServersService = &xTargetServersService{}
+
+func (s *xTargetServersService) Get(name string, env string) (*apigee.TargetServer, *apigee.Response, error) {
+	return s.send("GET", path.Join("environments", env, "targetservers", name), nil)
+}
+
+func (s *xTargetServersService) Create(ts apigee.TargetServer, env string) (*apigee.TargetServer, *apigee.Response, error) {
+	return s.send("POST", path.Join("environments", env, "targetservers"), newXTargetServer(ts))
+}
+
+func (s *xTargetServersService) Update(ts apigee.TargetServer, env string) (*apigee.TargetServer, *apigee.Response, error) {
+	return s.send("PUT", path.Join("environments", env, "targetservers", ts.Name), newXTargetServer(ts))
+}
+
+func (s *xTargetServersService) Delete(name string, env string) (*apigee.Response, error) {
+	return xDo(s.client, "DELETE", path.Join("environments", env, "targetservers", name), nil, nil, nil)
+}
+
+func (s *xTargetServersService) send(method string, targetServerPath string, body interface{}) (*apigee.TargetServer, *apigee.Response, error) {
+
+	target := xTargetServer{}
+	resp, err := xDo(s.client, method, targetServerPath, nil, body, &target)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return target.targetServer(), resp, nil
+}
+
+// Companies were replaced by app groups on Apigee X.
+type xCompaniesService struct{}
+
+var _ apigee.CompanyService = &xCompaniesService{}
+
+func (s *xCompaniesService) Get(string) (*apigee.Company, *apigee.Response, error) {
+	return nil, nil, unsupportedOnApigeeX("companies", "app groups")
+}
+
+func (s *xCompaniesService) Create(apigee.Company) (*apigee.Company, *apigee.Response, error) {
+	return nil, nil, unsupportedOnApigeeX("companies", "app groups")
+}
+
+func (s *xCompaniesService) Delete(string) (*apigee.Response, error) {
+	return nil, unsupportedOnApigeeX("companies", "app groups")
+}
+
+func (s *xCompaniesService) Update(apigee.Company) (*apigee.Company, *apigee.Response, error) {
+	return nil, nil, unsupportedOnApigeeX("companies", "app groups")
+}
+
+type xCompanyAppsService struct{}
+
+var _ apigee.CompanyAppService = &xCompanyAppsService{}
+
+func (s *xCompanyAppsService) Get(string, string) (*apigee.CompanyApp, *apigee.Response, error) {
+	return nil, nil, unsupportedOnApigeeX("company apps", "app group apps")
+}
+
+func (s *xCompanyAppsService) Create(string, apigee.CompanyApp) (*apigee.CompanyApp, *apigee.Response, error) {
+	return nil, nil, unsupportedOnApigeeX("company apps", "app group apps")
+}
+
+func (s *xCompanyAppsService) Delete(string, string) (*apigee.Response, error) {
+	return nil, unsupportedOnApigeeX("company apps", "app group apps")
+}
+
+func (s *xCompanyAppsService) Update(string, apigee.CompanyApp) (*apigee.CompanyApp, *apigee.Response, error) {
+	return nil, nil, unsupportedOnApigeeX("company apps", "app group apps")
+}
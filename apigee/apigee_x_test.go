@@ -0,0 +1,430 @@
+package apigee
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/zambien/go-apigee-edge"
+)
+
+// fakeApigeeX is an in memory Apigee X management API covering what the proxy resources use, plus a Google token
+// endpoint for service account credentials.
+type fakeApigeeX struct {
+	t *testing.T
+
+	mu          sync.Mutex
+	token       string
+	proxies     map[string][][]byte
+	deployments map[string]map[string]int // proxy -> env -> revision
+	targets     map[string]json.RawMessage
+
+	// state is the state of every deployment, READY when empty.
+	state string
+}
+
+func newFakeApigeeX(t *testing.T) *fakeApigeeX {
+	return &fakeApigeeX{
+		t:           t,
+		token:       "x-access-token",
+		proxies:     map[string][][]byte{},
+		deployments: map[string]map[string]int{},
+		targets:     map[string]json.RawMessage{},
+	}
+}
+
+func (f *fakeApigeeX) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		r.ParseForm()
+		if r.PostForm.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || len(strings.Split(r.PostForm.Get("assertion"), ".")) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":3600}`, f.token)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+f.token {
+		f.error(w, http.StatusUnauthorized, "UNAUTHENTICATED")
+		return
+	}
+
+	prefix := "/v1/organizations/test-org/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		f.error(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	route := r.Method + " " + strings.Join(placeholders(segments), "/")
+
+	switch route {
+	case "POST apis":
+		f.importProxy(w, r)
+	case "GET apis/*":
+		f.getProxy(w, segments[1])
+	case "DELETE apis/*":
+		if len(f.deployments[segments[1]]) > 0 {
+			f.error(w, http.StatusBadRequest, "FAILED_PRECONDITION")
+			return
+		}
+		delete(f.proxies, segments[1])
+		fmt.Fprintf(w, `{"name":%q}`, segments[1])
+	case "GET apis/*/revisions/*":
+		revisions := f.proxies[segments[1]]
+		rev, _ := strconv.Atoi(segments[3])
+		if rev < 1 || rev > len(revisions) || r.URL.Query().Get("format") != "bundle" {
+			f.error(w, http.StatusNotFound, "NOT_FOUND")
+			return
+		}
+		w.Write(revisions[rev-1])
+	case "GET apis/*/deployments":
+		deployments := []string{}
+		for env, rev := range f.deployments[segments[1]] {
+			deployments = append(deployments, fmt.Sprintf(`{"environment":%q,"apiProxy":%q,"revision":"%d","deployStartTime":"1600000000000","state":%q}`, env, segments[1], rev, f.deploymentState()))
+		}
+		fmt.Fprintf(w, `{"deployments":[%s]}`, strings.Join(deployments, ","))
+	case "POST environments/*/apis/*/revisions/*/deployments":
+		f.deploy(w, r, segments[1], segments[3], segments[5])
+	case "GET environments/*/apis/*/revisions/*/deployments":
+		if f.deploymentState() == "ERROR" {
+			fmt.Fprintf(w, `{"environment":%q,"apiProxy":%q,"revision":%q,"state":"ERROR","errors":[{"message":"fake deployment error"}]}`, segments[1], segments[3], segments[5])
+			return
+		}
+		fmt.Fprintf(w, `{"environment":%q,"apiProxy":%q,"revision":%q,"state":"READY","instances":[{"instance":"eval-instance","deployedRevisions":[{"revision":%q,"percentage":100}]}]}`, segments[1], segments[3], segments[5], segments[5])
+	case "DELETE environments/*/apis/*/revisions/*/deployments":
+		delete(f.deployments[segments[3]], segments[1])
+		w.Write([]byte("{}"))
+	case "GET developers/*/apps/*":
+		fmt.Fprintf(w, `{"name":%q,"appId":"app-id","keyExpiresIn":"-1","credentials":[{"consumerKey":"key","consumerSecret":"secret","expiresAt":"-1","issuedAt":"1600000000000","apiProducts":[{"apiproduct":"product","status":"approved"}]}]}`, segments[3])
+	case "POST environments/*/targetservers":
+		body, _ := ioutil.ReadAll(r.Body)
+		target := xTargetServer{}
+		if err := json.Unmarshal(body, &target); err != nil || strings.Contains(string(body), `"enabled":"`) {
+			f.error(w, http.StatusBadRequest, "INVALID_ARGUMENT")
+			return
+		}
+		f.targets[target.Name] = body
+		w.Write(body)
+	case "GET environments/*/targetservers/*":
+		target, ok := f.targets[segments[3]]
+		if !ok {
+			f.error(w, http.StatusNotFound, "NOT_FOUND")
+			return
+		}
+		w.Write(target)
+	default:
+		f.t.Errorf("fakeApigeeX unexpected request %s %s", r.Method, r.URL)
+		f.error(w, http.StatusNotImplemented, "UNIMPLEMENTED")
+	}
+}
+
+func (f *fakeApigeeX) deploymentState() string {
+
+	if f.state == "" {
+		return "READY"
+	}
+
+	return f.state
+}
+
+func (f *fakeApigeeX) error(w http.ResponseWriter, code int, status string) {
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"error":{"code":%d,"message":"fake error","status":%q}}`, code, status)
+}
+
+func (f *fakeApigeeX) importProxy(w http.ResponseWriter, r *http.Request) {
+
+	name := r.URL.Query().Get("name")
+	if r.URL.Query().Get("action") != "import" || name == "" {
+		f.error(w, http.StatusBadRequest, "INVALID_ARGUMENT")
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		f.error(w, http.StatusBadRequest, "INVALID_ARGUMENT")
+		return
+	}
+	bundle, _ := ioutil.ReadAll(file)
+
+	f.proxies[name] = append(f.proxies[name], bundle)
+	fmt.Fprintf(w, `{"name":%q,"revision":"%d","createdAt":"1600000000000","lastModifiedAt":"1600000000000"}`, name, len(f.proxies[name]))
+}
+
+func (f *fakeApigeeX) getProxy(w http.ResponseWriter, name string) {
+
+	revisions, ok := f.proxies[name]
+	if !ok {
+		f.error(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+
+	numbers := []string{}
+	for i := range revisions {
+		numbers = append(numbers, fmt.Sprintf(`"%d"`, i+1))
+	}
+	fmt.Fprintf(w, `{"name":%q,"revision":[%s],"latestRevisionId":"%d","metaData":{"createdAt":"1600000000000","lastModifiedAt":"1600000000000","subType":"Proxy"}}`,
+		name, strings.Join(numbers, ","), len(revisions))
+}
+
+func (f *fakeApigeeX) deploy(w http.ResponseWriter, r *http.Request, env string, name string, revision string) {
+
+	// Apigee X rejects the Edge only delay parameter.
+	if r.URL.Query().Get("delay") != "" {
+		f.error(w, http.StatusBadRequest, "INVALID_ARGUMENT")
+		return
+	}
+	rev, _ := strconv.Atoi(revision)
+	if rev < 1 || rev > len(f.proxies[name]) {
+		f.error(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	if deployed, ok := f.deployments[name][env]; ok && deployed != rev && r.URL.Query().Get("override") != "true" {
+		f.error(w, http.StatusBadRequest, "FAILED_PRECONDITION")
+		return
+	}
+
+	if f.deployments[name] == nil {
+		f.deployments[name] = map[string]int{}
+	}
+	f.deployments[name][env] = rev
+	fmt.Fprintf(w, `{"environment":%q,"apiProxy":%q,"revision":%q,"deployStartTime":"1600000000000"}`, env, name, revision)
+}
+
+func placeholders(segments []string) []string {
+
+	route := make([]string, len(segments))
+	for i, segment := range segments {
+		route[i] = segment
+		if i%2 == 1 {
+			route[i] = "*"
+		}
+	}
+
+	return route
+}
+
+// writeServiceAccountKey writes a service account key file whose tokens come from the fake server.
+func writeServiceAccountKey(t *testing.T, server *httptest.Server) string {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	credentials, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "test-org",
+		"private_key_id": "fake",
+		"private_key":    string(keyPem),
+		"client_email":   "terraform@test-org.iam.gserviceaccount.com",
+		"token_uri":      server.URL + "/token",
+	})
+
+	dir, err := ioutil.TempDir("", "apigee-x-")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	filename := filepath.Join(dir, "credentials.json")
+	if err := ioutil.WriteFile(filename, credentials, 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return filename
+}
+
+func newFakeApigeeXClient(t *testing.T, server *httptest.Server) *apigee.EdgeClient {
+
+	config := Config{
+		Flavor:      flavorX,
+		BaseURI:     server.URL,
+		Org:         "test-org",
+		Credentials: writeServiceAccountKey(t, server),
+	}
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return client
+}
+
+func TestApigeeXProxyDeployment(t *testing.T) {
+
+	fake := newFakeApigeeX(t)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	providers := map[string]terraform.ResourceProvider{
+		"apigee": Provider().(*schema.Provider),
+	}
+	config := fmt.Sprintf(testApigeeXProxyDeploymentConfig, server.URL, writeServiceAccountKey(t, server))
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: providers,
+		CheckDestroy: func(s *terraform.State) error {
+			if len(fake.proxies) != 0 {
+				return fmt.Errorf("proxies still exist: %v", fake.proxies)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_api_proxy.helloworld", "revision", "1"),
+					resource.TestCheckResourceAttr("apigee_api_proxy_deployment.helloworld", "revision", "1"),
+					func(s *terraform.State) error {
+						if fake.deployments["helloworld-x"]["test"] != 1 {
+							return fmt.Errorf("expected revision 1 to be deployed, got: %v", fake.deployments)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestApigeeXDeploymentError(t *testing.T) {
+
+	fake := newFakeApigeeX(t)
+	fake.state = "ERROR"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	providers := map[string]terraform.ResourceProvider{
+		"apigee": Provider().(*schema.Provider),
+	}
+	config := fmt.Sprintf(testApigeeXProxyDeploymentConfig, server.URL, writeServiceAccountKey(t, server))
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: providers,
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile("revision 1 of helloworld-x failed to deploy in test: test \\(deployment\\): error: fake deployment error"),
+			},
+			{
+				PreConfig: func() {
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					fake.state = ""
+				},
+				Config: config,
+			},
+		},
+	})
+
+	client := newFakeApigeeXClient(t, server)
+	fake.mu.Lock()
+	fake.state = "ERROR"
+	fake.deployments["helloworld-x"] = map[string]int{"test": 1}
+	fake.mu.Unlock()
+	deployments, _, err := client.Proxies.GetDeployments("helloworld-x")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if state := deployments.Environments[0].Revision[0].State; state != "error" {
+		t.Fatalf("expected the deployment state to be read, got %q", state)
+	}
+}
+
+const testApigeeXProxyDeploymentConfig = `
+provider "apigee" {
+   flavor       = "x"
+   base_uri     = "%s"
+   org          = "test-org"
+   credentials  = "%s"
+}
+
+resource "apigee_api_proxy" "helloworld" {
+   name         = "helloworld-x"
+   bundle       = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+}
+
+resource "apigee_api_proxy_deployment" "helloworld" {
+   proxy_name     = "${apigee_api_proxy.helloworld.name}"
+   env            = "test"
+   revision       = "${apigee_api_proxy.helloworld.revision}"
+   wait_for_ready = true
+}
+`
+
+func TestApigeeXDeveloperAppTimestamps(t *testing.T) {
+
+	server := httptest.NewServer(newFakeApigeeX(t))
+	defer server.Close()
+
+	app, _, err := newFakeApigeeXClient(t, server).DeveloperApps.Get("someone@example.com", "my-app")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(app.Credentials) != 1 || app.Credentials[0].ExpiresAt != -1 || app.Credentials[0].IssuedAt != 1600000000000 {
+		t.Fatalf("unexpected credentials: %+v", app.Credentials)
+	}
+	if app.KeyExpiresIn != -1 {
+		t.Fatalf("expected keyExpiresIn -1, got %d", app.KeyExpiresIn)
+	}
+}
+
+func TestApigeeXTargetServerSSLInfo(t *testing.T) {
+
+	server := httptest.NewServer(newFakeApigeeX(t))
+	defer server.Close()
+	client := newFakeApigeeXClient(t, server)
+
+	target := apigee.TargetServer{
+		Name:    "backend",
+		Host:    "backend.example.com",
+		Port:    443,
+		Enabled: true,
+		SSLInfo: &apigee.SSLInfo{SSLEnabled: "true", ClientAuthEnabled: "false"},
+	}
+	if _, _, err := client.TargetServers.Create(target, "test"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	created, _, err := client.TargetServers.Get("backend", "test")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if created.SSLInfo == nil || created.SSLInfo.SSLEnabled != "true" || created.SSLInfo.ClientAuthEnabled != "false" {
+		t.Fatalf("unexpected ssl info: %+v", created.SSLInfo)
+	}
+}
+
+func TestApigeeXCompaniesUnsupported(t *testing.T) {
+
+	server := httptest.NewServer(newFakeApigeeX(t))
+	defer server.Close()
+
+	_, _, err := newFakeApigeeXClient(t, server).Companies.Get("acme")
+	if err == nil || !strings.Contains(err.Error(), "not supported on Apigee X") {
+		t.Fatalf("expected companies to be unsupported, got: %v", err)
+	}
+}
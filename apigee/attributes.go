@@ -0,0 +1,149 @@
+package apigee
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+const (
+	attributesAuthoritative = "authoritative"
+	attributesAdditive      = "additive"
+)
+
+// defaultAttributes returns the provider's default_attributes.
+func defaultAttributes(meta interface{}) map[string]string {
+
+	clients, ok := meta.(*apigeeClients)
+	if !ok {
+		return nil
+	}
+
+	return clients.config.DefaultAttributes
+}
+
+// attributesWithDefaults returns the attributes to send for a resource: the attributes in current (what Apigee has)
+// the resource leaves alone, then the provider's default_attributes, then the resource's own attributes.
+func attributesWithDefaults(d *schema.ResourceData, meta interface{}, current []apigee.Attribute) []apigee.Attribute {
+
+	attributes := map[string]interface{}{}
+	for _, attribute := range current {
+		if !managesAttribute(attribute.Name, d, meta, true) {
+			attributes[attribute.Name] = attribute.Value
+		}
+	}
+	for k, v := range defaultAttributes(meta) {
+		attributes[k] = v
+	}
+	if v, ok := d.Get("attributes").(map[string]interface{}); ok {
+		for k, v := range v {
+			attributes[k] = v
+		}
+	}
+
+	return attributesFromMap(attributes)
+}
+
+// flattenAttributes returns the attributes read from Apigee as the resource's attributes, leaving out those the
+// resource does not manage so that they never show up as a diff.
+func flattenAttributes(attributes []apigee.Attribute, d *schema.ResourceData, meta interface{}) map[string]interface{} {
+
+	result := make(map[string]interface{}, len(attributes))
+	for _, attribute := range attributes {
+		if managesAttribute(attribute.Name, d, meta, false) {
+			result[attribute.Name] = attribute.Value
+		}
+	}
+
+	return result
+}
+
+// managesAttribute reports whether the resource owns the attribute name.  Ignored attributes are never owned.  In
+// additive mode only the attributes the resource declares are, together with those it declared before the change
+// being applied when withPrior is set, so that attributes removed from the configuration get removed from Apigee.
+// Default attributes are owned only when the resource declares them too.
+func managesAttribute(name string, d *schema.ResourceData, meta interface{}, withPrior bool) bool {
+
+	if ignored, ok := d.GetOk("ignore_attributes"); ok && ignored.(*schema.Set).Contains(name) {
+		return false
+	}
+
+	declared, _ := d.Get("attributes").(map[string]interface{})
+	if _, ok := declared[name]; ok {
+		return true
+	}
+	if withPrior {
+		prior, _ := d.GetChange("attributes")
+		if _, ok := prior.(map[string]interface{})[name]; ok {
+			return true
+		}
+	}
+
+	_, isDefault := defaultAttributes(meta)[name]
+	_, wasDefault := d.Get("default_attributes").(map[string]interface{})[name]
+	if withPrior {
+		// A default the provider no longer sets is removed.
+		prior, _ := d.GetChange("default_attributes")
+		if _, ok := prior.(map[string]interface{})[name]; ok && !isDefault {
+			return true
+		}
+	}
+
+	if d.Get("attributes_mode").(string) == attributesAdditive {
+		return false
+	}
+
+	return !isDefault && !wasDefault
+}
+
+// flattenDefaultAttributes returns the default attributes Apigee has for a resource that does not declare them, the
+// provider's default_attributes as applied.  Defaults the provider set before stay until an update removes them.
+func flattenDefaultAttributes(attributes []apigee.Attribute, d *schema.ResourceData, meta interface{}) map[string]interface{} {
+
+	defaults := defaultAttributes(meta)
+	prior, _ := d.Get("default_attributes").(map[string]interface{})
+	ignored := d.Get("ignore_attributes").(*schema.Set)
+	declared, _ := d.Get("attributes").(map[string]interface{})
+
+	result := map[string]interface{}{}
+	for _, attribute := range attributes {
+		_, isDefault := defaults[attribute.Name]
+		_, wasDefault := prior[attribute.Name]
+		_, isDeclared := declared[attribute.Name]
+		if (isDefault || wasDefault) && !isDeclared && !ignored.Contains(attribute.Name) {
+			result[attribute.Name] = attribute.Value
+		}
+	}
+
+	return result
+}
+
+// customizeDefaultAttributes plans default_attributes to be the provider's default_attributes the resource does not
+// declare or ignore, so that adding, changing or removing a default updates the resource.
+func customizeDefaultAttributes(d *schema.ResourceDiff, meta interface{}) error {
+
+	if !d.NewValueKnown("attributes") || !d.NewValueKnown("ignore_attributes") {
+		return nil
+	}
+
+	ignored := d.Get("ignore_attributes").(*schema.Set)
+	declared, _ := d.Get("attributes").(map[string]interface{})
+
+	expected := map[string]interface{}{}
+	for k, v := range defaultAttributes(meta) {
+		if _, isDeclared := declared[k]; !isDeclared && !ignored.Contains(k) {
+			expected[k] = v
+		}
+	}
+	if reflect.DeepEqual(expected, d.Get("default_attributes").(map[string]interface{})) {
+		return nil
+	}
+
+	return d.SetNew("default_attributes", expected)
+}
+
+// preservesAttributes is true when an update has to keep attributes Apigee has that the resource does not manage.
+func preservesAttributes(d *schema.ResourceData) bool {
+	return d.Get("attributes_mode").(string) == attributesAdditive || d.Get("ignore_attributes").(*schema.Set).Len() > 0
+}
@@ -0,0 +1,190 @@
+package apigee
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestDefaultAttributes(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, `default_attributes = {
+      managed_by = "terraform"
+      owner      = "platform"
+   }`) + testDefaultAttributesConfig
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				// The plan after apply must be empty, defaults the resources do not declare cause no diff.
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_developer.foo", "attributes.%", "1"),
+					resource.TestCheckResourceAttr("apigee_developer.foo", "attributes.team", "payments"),
+					resource.TestCheckResourceAttr("apigee_developer_app.foo", "attributes.%", "1"),
+					resource.TestCheckResourceAttr("apigee_developer_app.foo", "attributes.owner", "payments"),
+					func(s *terraform.State) error {
+						expected := map[string]map[string]string{
+							"developers/foo@example.com":              {"managed_by": "terraform", "owner": "platform", "team": "payments"},
+							"developers/foo@example.com/apps/foo-app": {"managed_by": "terraform", "owner": "payments"},
+						}
+						for path, attributes := range expected {
+							if actual := fake.attributes(path); fmt.Sprint(actual) != fmt.Sprint(attributes) {
+								return fmt.Errorf("expected %s to have attributes %v, got %v", path, attributes, actual)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+const testDefaultAttributesConfig = `
+resource "apigee_developer" "foo" {
+   email      = "foo@example.com"
+   first_name = "Foo"
+   last_name  = "Bar"
+   user_name  = "foo"
+   attributes = {
+      team = "payments"
+   }
+}
+
+resource "apigee_developer_app" "foo" {
+   developer_email = "${apigee_developer.foo.email}"
+   name            = "foo-app"
+   attributes = {
+      owner = "payments"
+   }
+}
+`
+
+func TestDefaultAttributesChange(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	company := fmt.Sprintf(testAttributesModeConfig, "additive", `team = "payments"`, "")
+	developer := "developers/foo@example.com"
+	path := "companies/foo-company"
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fakeEdgeProvider(server, `default_attributes = { cost_center = "1234" }`) + testDefaultAttributesConfig + company,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_company.foo", "default_attributes.cost_center", "1234"),
+					testCheckFakeAttributes(fake, path, map[string]string{"cost_center": "1234", "team": "payments"}),
+				),
+			},
+			{
+				// A changed default is applied to every resource.
+				Config: fakeEdgeProvider(server, `default_attributes = { cost_center = "5678" }`) + testDefaultAttributesConfig + company,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_company.foo", "default_attributes.cost_center", "5678"),
+					resource.TestCheckResourceAttr("apigee_developer.foo", "default_attributes.cost_center", "5678"),
+					testCheckFakeAttributes(fake, path, map[string]string{"cost_center": "5678", "team": "payments"}),
+					testCheckFakeAttributes(fake, developer, map[string]string{"cost_center": "5678", "team": "payments"}),
+				),
+			},
+			{
+				// A removed default is removed, in additive mode too.
+				Config: fakeEdgeProvider(server, "") + testDefaultAttributesConfig + company,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_company.foo", "default_attributes.%", "0"),
+					testCheckFakeAttributes(fake, path, map[string]string{"team": "payments"}),
+					testCheckFakeAttributes(fake, developer, map[string]string{"team": "payments"}),
+				),
+			},
+		},
+	})
+}
+
+func TestAttributesModeAdditive(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+	path := "companies/foo-company"
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testAttributesModeConfig, "additive", `team = "payments"`, ""),
+			},
+			{
+				// Attributes added outside of Terraform are neither read nor wiped.
+				PreConfig: func() { fake.setAttribute(path, "Notes", "added in the portal") },
+				Config:    provider + fmt.Sprintf(testAttributesModeConfig, "additive", `team = "billing"`, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_company.foo", "attributes.%", "1"),
+					testCheckFakeAttributes(fake, path, map[string]string{"Notes": "added in the portal", "team": "billing"}),
+				),
+			},
+			{
+				// Attributes removed from the configuration are removed from Apigee.
+				Config: provider + fmt.Sprintf(testAttributesModeConfig, "additive", `owner = "platform"`, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeAttributes(fake, path, map[string]string{"Notes": "added in the portal", "owner": "platform"}),
+				),
+			},
+		},
+	})
+}
+
+func TestAttributesIgnored(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+	path := "companies/foo-company"
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testAttributesModeConfig, "authoritative", `team = "payments"`, `"DisplayName"`),
+			},
+			{
+				PreConfig: func() { fake.setAttribute(path, "DisplayName", "Foo Company") },
+				Config:    provider + fmt.Sprintf(testAttributesModeConfig, "authoritative", `team = "billing"`, `"DisplayName"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_company.foo", "attributes.%", "1"),
+					testCheckFakeAttributes(fake, path, map[string]string{"DisplayName": "Foo Company", "team": "billing"}),
+				),
+			},
+			{
+				// Authoritative mode still owns every attribute that is not ignored.
+				PreConfig: func() { fake.setAttribute(path, "Notes", "added in the portal") },
+				Config:    provider + fmt.Sprintf(testAttributesModeConfig, "authoritative", `team = "billing"`, `"DisplayName"`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeAttributes(fake, path, map[string]string{"DisplayName": "Foo Company", "team": "billing"}),
+				),
+			},
+		},
+	})
+}
+
+func testCheckFakeAttributes(fake *fakeEdge, path string, expected map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if actual := fake.attributes(path); fmt.Sprint(actual) != fmt.Sprint(expected) {
+			return fmt.Errorf("expected %s to have attributes %v, got %v", path, expected, actual)
+		}
+		return nil
+	}
+}
+
+const testAttributesModeConfig = `
+resource "apigee_company" "foo" {
+   name              = "foo-company"
+   attributes_mode   = "%s"
+   attributes        = {
+      %s
+   }
+   ignore_attributes = [%s]
+}
+`
@@ -0,0 +1,172 @@
+package apigee
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+const (
+	proxiesPath     = "apis"
+	sharedFlowsPath = "sharedflows"
+)
+
+// exportRevision downloads a revision of a proxy or shared flow as a zip bundle.
+// go-apigee-edge's Export writes a timestamped file into the working directory, so the request is made here instead.
+func exportRevision(client *apigee.EdgeClient, resourcePath string, name string, rev apigee.Revision) ([]byte, error) {
+
+	exportURL, err := url.Parse(path.Join(resourcePath, name, "revisions", rev.String()))
+	if err != nil {
+		return nil, err
+	}
+	q := exportURL.Query()
+	q.Add("format", "bundle")
+	exportURL.RawQuery = q.Encode()
+
+	req, err := client.NewRequest("GET", exportURL.String(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Del("Accept")
+
+	buf := new(bytes.Buffer)
+	if _, err := client.Do(req, buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// descriptorRewrites match what Apigee rewrites in the top level descriptor (e.g. apiproxy/helloworld.xml) on every
+// import: the revision number, who created and last modified the revision and when, and the manifest's hash.
+var descriptorRewrites = []*regexp.Regexp{
+	regexp.MustCompile(`^(<\?xml[^>]*>\s*)?(<(?:APIProxy|SharedFlowBundle)\b[^>]*?)\s+revision="[^"]*"`),
+	regexp.MustCompile(`(?m)^\s*<(?:CreatedAt|CreatedBy|LastModifiedAt|LastModifiedBy|ManifestVersion)>[^<]*</(?:CreatedAt|CreatedBy|LastModifiedAt|LastModifiedBy|ManifestVersion)>\s*$\n?`),
+	regexp.MustCompile(`<(?:CreatedAt|CreatedBy|LastModifiedAt|LastModifiedBy|ManifestVersion)>[^<]*</(?:CreatedAt|CreatedBy|LastModifiedAt|LastModifiedBy|ManifestVersion)>`),
+	regexp.MustCompile(`<(?:CreatedAt|CreatedBy|LastModifiedAt|LastModifiedBy|ManifestVersion)\s*/>`),
+}
+
+// normalizeDescriptor removes what Apigee rewrites from a top level descriptor.
+func normalizeDescriptor(contents []byte) []byte {
+
+	contents = descriptorRewrites[0].ReplaceAll(contents, []byte("$1$2"))
+	for _, rewrite := range descriptorRewrites[1:] {
+		contents = rewrite.ReplaceAll(contents, nil)
+	}
+
+	return contents
+}
+
+// bundleSha returns a hash of the contents of a zip bundle which is stable across exports.  Zip metadata is ignored
+// and the top level descriptor (e.g. apiproxy/helloworld.xml) is normalized, see normalizeDescriptor.  Apigee names
+// the descriptor after the proxy so it is hashed whatever its name.
+func bundleSha(bundle []byte) (string, error) {
+
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		return "", fmt.Errorf("error reading bundle: %s", err.Error())
+	}
+
+	files := make(map[string]*zip.File)
+	names := []string{}
+	for _, f := range archive.File {
+		name := strings.TrimPrefix(path.Clean("/"+f.Name), "/")
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if isDescriptor(name) {
+			// Hashed as the bundle's directory, e.g. apiproxy/.
+			name = path.Dir(name) + "/"
+		}
+		files[name] = f
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		rc, err := files[name].Open()
+		if err != nil {
+			return "", fmt.Errorf("error reading %s from bundle: %s", name, err.Error())
+		}
+		contents, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return "", fmt.Errorf("error reading %s from bundle: %s", name, err.Error())
+		}
+
+		contents = bytes.Replace(contents, []byte("\r\n"), []byte("\n"), -1)
+		if strings.HasSuffix(name, "/") {
+			contents = normalizeDescriptor(contents)
+		}
+		contents = bytes.TrimSpace(contents)
+
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(contents))
+		h.Write(contents)
+	}
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// isDescriptor is true for the top level descriptor of a bundle, e.g. apiproxy/helloworld.xml.
+func isDescriptor(name string) bool {
+	return strings.Count(name, "/") == 1 && strings.HasSuffix(name, ".xml")
+}
+
+// bundleFileSha returns the bundleSha of a zip bundle on disk.
+func bundleFileSha(filename string) (string, error) {
+
+	bundle, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	return bundleSha(bundle)
+}
+
+// bundleStateFunc stores the path of a bundle as its bundleSha.  A bundle that cannot be read is stored as is, the
+// import reports the problem.
+func bundleStateFunc(v interface{}) string {
+
+	sha, err := bundleFileSha(v.(string))
+	if err != nil {
+		log.Printf("[DEBUG] bundleStateFunc unable to hash bundle %s: %s", v.(string), err.Error())
+		return v.(string)
+	}
+
+	return sha
+}
+
+// writeBundle saves an exported bundle to output_path and records its hashes.
+func writeBundle(d *schema.ResourceData, bundle []byte) error {
+
+	outputPath := d.Get("output_path").(string)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("error creating directory for %s: %s", outputPath, err.Error())
+	}
+	if err := ioutil.WriteFile(outputPath, bundle, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %s", outputPath, err.Error())
+	}
+
+	sum := sha256.Sum256(bundle)
+	d.Set("output_sha256", hex.EncodeToString(sum[:]))
+	d.Set("output_base64sha256", base64.StdEncoding.EncodeToString(sum[:]))
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package apigee
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+)
+
+func testBundle(t *testing.T, modified time.Time, files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for name, contents := range files {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Modified: modified, Method: zip.Deflate})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		f.Write([]byte(contents))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestBundleSha(t *testing.T) {
+	local := testBundle(t, time.Date(2017, 9, 20, 21, 54, 0, 0, time.UTC), map[string]string{
+		"apiproxy/helloworld.xml":        `<APIProxy revision="1" name="helloworld"></APIProxy>`,
+		"apiproxy/policies/add-cors.xml": "<AssignMessage name=\"add-cors\"/>\r\n",
+		"apiproxy/proxies/default.xml":   `<ProxyEndpoint name="default"/>`,
+	})
+	exported := testBundle(t, time.Now(), map[string]string{
+		"apiproxy/":                      "",
+		"apiproxy/helloworld.xml":        `<APIProxy revision="7" name="helloworld"><CreatedAt>1505943749599</CreatedAt></APIProxy>`,
+		"apiproxy/policies/add-cors.xml": "<AssignMessage name=\"add-cors\"/>\n",
+		"apiproxy/proxies/default.xml":   `<ProxyEndpoint name="default"/>`,
+	})
+	edited := testBundle(t, time.Now(), map[string]string{
+		"apiproxy/helloworld.xml":        `<APIProxy revision="7" name="helloworld"/>`,
+		"apiproxy/policies/add-cors.xml": `<AssignMessage name="add-cors" enabled="false"/>`,
+		"apiproxy/proxies/default.xml":   `<ProxyEndpoint name="default"/>`,
+	})
+	editedDescriptor := testBundle(t, time.Now(), map[string]string{
+		"apiproxy/helloworld.xml":        `<APIProxy revision="7" name="helloworld"><Basepaths>/v1/hello</Basepaths></APIProxy>`,
+		"apiproxy/policies/add-cors.xml": "<AssignMessage name=\"add-cors\"/>\n",
+		"apiproxy/proxies/default.xml":   `<ProxyEndpoint name="default"/>`,
+	})
+
+	localSha, err := bundleSha(local)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	exportedSha, err := bundleSha(exported)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	editedSha, err := bundleSha(edited)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if localSha != exportedSha {
+		t.Fatalf("expected exported revision to match the local bundle: %s != %s", exportedSha, localSha)
+	}
+	if localSha == editedSha {
+		t.Fatalf("expected edited revision to differ from the local bundle")
+	}
+	if sha, _ := bundleSha(editedDescriptor); sha == localSha {
+		t.Fatalf("expected revision with an edited descriptor to differ from the local bundle")
+	}
+
+	if _, err := bundleSha([]byte("not a zip")); err == nil {
+		t.Fatalf("expected an error hashing an invalid bundle")
+	}
+}
+
+func TestNormalizeDescriptor(t *testing.T) {
+	local := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<APIProxy name="helloworld">
+    <Basepaths>/v0/hello</Basepaths>
+    <Description></Description>
+</APIProxy>`
+	exported := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<APIProxy revision="3" name="helloworld">
+    <Basepaths>/v0/hello</Basepaths>
+    <CreatedAt>1505943749599</CreatedAt>
+    <CreatedBy>someone@example.com</CreatedBy>
+    <Description></Description>
+    <LastModifiedAt>1505943762414</LastModifiedAt>
+    <LastModifiedBy>someone@example.com</LastModifiedBy>
+    <ManifestVersion>SHA-512:37f17c</ManifestVersion>
+</APIProxy>`
+
+	if actual := string(normalizeDescriptor([]byte(exported))); actual != local {
+		t.Fatalf("expected the exported descriptor to normalize to\n%s\ngot\n%s", local, actual)
+	}
+}
+
+func TestBundleFileSha(t *testing.T) {
+	sha, err := bundleFileSha("test-fixtures/helloworld_proxy.zip")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if sha == "" {
+		t.Fatalf("expected a sha for test-fixtures/helloworld_proxy.zip")
+	}
+}
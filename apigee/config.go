@@ -1,28 +1,224 @@
 package apigee
 
 import (
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/zambien/go-apigee-edge"
 	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
-// Config holds API and APP keys to authenticate to Datadog.
+// Config holds the settings needed to authenticate to the Apigee management API.
 type Config struct {
-	BaseURI string
-	User    string
-	Pass    string
-	Org     string
+	Flavor      string
+	BaseURI     string
+	User        string
+	Pass        string
+	AccessToken string
+	Org         string
+
+	// OAuth2 password grant.  Used when OAuthTokenURI, MfaToken or RefreshToken is set.
+	OAuthTokenURI string
+	MfaToken      string
+	RefreshToken  string
+
+	// SAML SSO one time passcode exchange.
+	SSOZone     string
+	SSOPasscode string
+
+	// Apigee X service account key, a file path or its JSON contents.
+	Credentials string
+
+	// Retries of requests which failed for transient reasons.
+	MaxRetries   int
+	RetryMinWait time.Duration
+	RetryMaxWait time.Duration
+
+	// Client side throttling, 0 means unlimited.
+	RequestsPerSecond     float64
+	MaxConcurrentRequests int
+
+	// TLS and proxy settings of the connection to the management API.
+	CACertFile         string
+	ClientCertFile     string
+	ClientKeyFile      string
+	InsecureSkipVerify bool
+	HTTPProxy          string
+	NoProxy            string
+
+	// Log every request and response, secrets redacted.
+	DebugHTTP bool
+
+	// Attributes merged into those of every product, developer, app and company.
+	DefaultAttributes map[string]string
+}
+
+func (c *Config) useOAuth() bool {
+	return c.OAuthTokenURI != "" || c.MfaToken != "" || c.RefreshToken != "" || c.SSOPasscode != ""
 }
 
-// Client returns a new Apigee client.
+// Client returns a new Apigee client for the configured organization.
 func (c *Config) Client() (*apigee.EdgeClient, error) {
 
-	auth := apigee.EdgeAuth{Username: c.User, Password: c.Pass}
-	opts := &apigee.EdgeClientOptions{MgmtUrl: c.BaseURI, Org: c.Org, Auth: &auth, Debug: false}
+	clients, err := c.Clients()
+	if err != nil {
+		return nil, err
+	}
+
+	return clients.defaultClient(), nil
+}
+
+// Clients returns the clients of every organization the provider manages, starting with the configured one.
+func (c *Config) Clients() (*apigeeClients, error) {
+
+	transport, err := c.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	clients := &apigeeClients{config: c, transport: transport, clients: map[string]*apigee.EdgeClient{}}
+	if _, err := clients.client(c.Org); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// newClient returns a client for org which sends its requests through transport.
+func (c *Config) newClient(org string, transport http.RoundTripper) (*apigee.EdgeClient, error) {
+
+	auth := apigee.EdgeAuth{Username: c.User, Password: c.Pass, AccessToken: c.AccessToken}
+	if c.useOAuth() || c.useGoogleAuth() {
+		// The Authorization header is replaced on every request by oauthTransport, this only keeps
+		// go-apigee-edge from falling back to .netrc.
+		auth = apigee.EdgeAuth{AccessToken: "oauth"}
+	}
+	// go-apigee-edge's own debug output dumps requests as they are, secrets included, debugTransport logs them instead.
+	opts := &apigee.EdgeClientOptions{MgmtUrl: c.BaseURI, Org: org, Auth: &auth, Debug: false, PesterClient: newPesterClient(transport)}
 	client, err := apigee.NewEdgeClient(opts)
 	if err != nil {
 		log.Printf("while initializing Edge client, error:\n%#v\n", err)
 		return client, err
 	}
 
+	if c.Flavor == flavorX {
+		if err := useApigeeX(client, c.BaseURI, org); err != nil {
+			log.Printf("while initializing Apigee X client, error:\n%#v\n", err)
+			return nil, err
+		}
+	}
+
 	return client, nil
 }
+
+// apigeeClients hands out one client per organization.  The clients share a transport so that tokens, retries and
+// throttling hold across organizations.
+type apigeeClients struct {
+	config    *Config
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	clients map[string]*apigee.EdgeClient
+}
+
+// client returns the client for org, the configured organization when org is empty.
+func (c *apigeeClients) client(org string) (*apigee.EdgeClient, error) {
+
+	if org == "" {
+		org = c.config.Org
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[org]; ok {
+		return client, nil
+	}
+	client, err := c.config.newClient(org, c.transport)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[org] = client
+
+	return client, nil
+}
+
+// defaultClient returns the client for the configured organization.
+func (c *apigeeClients) defaultClient() *apigee.EdgeClient {
+
+	client, _ := c.client("")
+	return client
+}
+
+// orgClient returns the client for the organization a resource or data source names in org, the provider's
+// organization when it names none.
+func orgClient(d interface{ Get(string) interface{} }, meta interface{}) (*apigee.EdgeClient, error) {
+	return meta.(*apigeeClients).client(d.Get("org").(string))
+}
+
+// orgName is the organization a resource or data source lives in, the provider's when it names none.  Reads store it
+// in org, so a resource keeps the organization it was created in until org is set to another one.
+func orgName(d interface{ Get(string) interface{} }, meta interface{}) string {
+	if org := d.Get("org").(string); org != "" {
+		return org
+	}
+	return meta.(*apigeeClients).config.Org
+}
+
+// importOrg takes the organization off an import ID given as {org}/{id}, so that resources of an organization other
+// than the provider's can be imported, and leaves the ID the resource is created with.
+func importOrg(d *schema.ResourceData) {
+	if i := strings.Index(d.Id(), "/"); i > 0 {
+		d.Set("org", d.Id()[:i])
+		d.SetId(d.Id()[i+1:])
+	}
+}
+
+// useGoogleAuth is true for Apigee X unless a ready made access token is given.
+func (c *Config) useGoogleAuth() bool {
+	return c.Flavor == flavorX && (c.Credentials != "" || c.AccessToken == "")
+}
+
+// transport builds the chain of round trippers every management API request goes through.
+func (c *Config) transport() (http.RoundTripper, error) {
+
+	base, err := c.httpTransport()
+	if err != nil {
+		return nil, err
+	}
+	var transport http.RoundTripper = base
+
+	if c.DebugHTTP {
+		transport = &debugTransport{base: transport}
+	}
+
+	if c.RequestsPerSecond > 0 || c.MaxConcurrentRequests > 0 {
+		transport = newThrottleTransport(c.RequestsPerSecond, c.MaxConcurrentRequests, transport)
+	}
+
+	if c.useGoogleAuth() {
+		source, err := newGoogleTokenSource(c.Credentials, transport)
+		if err != nil {
+			return nil, err
+		}
+		transport = &oauthTransport{source: source, base: transport}
+	} else if c.useOAuth() {
+		source := newOAuthTokenSource(c, transport)
+		transport = &oauthTransport{source: source, base: transport}
+	}
+
+	if c.MaxRetries > 0 {
+		transport = &retryTransport{
+			maxRetries: c.MaxRetries,
+			minWait:    c.RetryMinWait,
+			maxWait:    c.RetryMaxWait,
+			base:       transport,
+		}
+	}
+
+	transport = &errorBodyTransport{base: transport}
+
+	return transport, nil
+}
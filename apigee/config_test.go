@@ -0,0 +1,62 @@
+package apigee
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestClientsPerOrganization(t *testing.T) {
+
+	var mu sync.Mutex
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		fmt.Fprint(w, `{"name":"helloworld"}`)
+	}))
+	defer server.Close()
+
+	config := Config{BaseURI: server.URL, Org: "test-org", AccessToken: "token"}
+	clients, err := config.Clients()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	other, err := clients.client("other-org")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if again, _ := clients.client("other-org"); again != other {
+		t.Fatal("expected the client of an organization to be reused")
+	}
+	if def, _ := clients.client(""); def != clients.defaultClient() {
+		t.Fatal("expected an empty organization to mean the provider's")
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceTargetServer().Schema, map[string]interface{}{"org": "other-org", "name": "helloworld", "env": "test"})
+	client, err := orgClient(d, clients)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if client != other {
+		t.Fatal("expected org to select the client of that organization")
+	}
+
+	if _, _, err := clients.defaultClient().Proxies.Get("helloworld"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, _, err := client.Proxies.Get("helloworld"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"/v1/o/test-org/apis/helloworld", "/v1/o/other-org/apis/helloworld"}
+	if len(paths) != len(expected) || paths[0] != expected[0] || paths[1] != expected[1] {
+		t.Fatalf("expected requests to %v, got %v", expected, paths)
+	}
+}
@@ -0,0 +1,9 @@
+package apigee
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceApiProxyBundle() *schema.Resource {
+	return dataSourceBundle(proxiesPath, "proxy_name")
+}
@@ -0,0 +1,68 @@
+package apigee
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccProxyBundleDataSource_Deployed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccCheckProxyBundleDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBundleFileExists("data.apigee_api_proxy_bundle.foo_bundle"),
+					resource.TestCheckResourceAttr(
+						"data.apigee_api_proxy_bundle.foo_bundle", "revision", "1"),
+					resource.TestCheckResourceAttrSet(
+						"data.apigee_api_proxy_bundle.foo_bundle", "output_sha256"),
+					resource.TestCheckResourceAttrSet(
+						"data.apigee_api_proxy_bundle.foo_bundle", "output_base64sha256"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBundleFileExists(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		r, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		outputPath := r.Primary.Attributes["output_path"]
+		if _, err := os.Stat(outputPath); err != nil {
+			return fmt.Errorf("Bundle was not exported to %s: %s", outputPath, err)
+		}
+		os.Remove(outputPath)
+
+		return nil
+	}
+}
+
+const testAccCheckProxyBundleDataSourceConfig = `
+resource "apigee_api_proxy" "foo_api_proxy" {
+   name  		= "foo_proxy_bundle_terraformed"
+   bundle       = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+}
+
+resource "apigee_api_proxy_deployment" "foo_api_proxy_deployment" {
+   proxy_name   = "${apigee_api_proxy.foo_api_proxy.name}"
+   env          = "test"
+   revision     = "${apigee_api_proxy.foo_api_proxy.revision}"
+}
+
+data "apigee_api_proxy_bundle" "foo_bundle" {
+   proxy_name   = "${apigee_api_proxy_deployment.foo_api_proxy_deployment.proxy_name}"
+   env          = "${apigee_api_proxy_deployment.foo_api_proxy_deployment.env}"
+   output_path  = "test-fixtures/exported/foo_proxy_bundle_terraformed.zip"
+}
+`
@@ -0,0 +1,106 @@
+package apigee
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+// dataSourceBundle exports a revision of a proxy or shared flow, named by nameKey, as a zip bundle.
+func dataSourceBundle(resourcePath string, nameKey string) *schema.Resource {
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			return dataSourceBundleRead(d, meta, resourcePath, nameKey)
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			nameKey: {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"revision": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"env"},
+			},
+			"env": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"revision"},
+			},
+			"output_path": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"output_sha256": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"output_base64sha256": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceBundleRead(d *schema.ResourceData, meta interface{}, resourcePath string, nameKey string) error {
+	log.Printf("[DEBUG] dataSourceBundleRead START %s", resourcePath)
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] dataSourceBundleRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
+
+	name := d.Get(nameKey).(string)
+
+	//Export the requested revision, the revision deployed to env or the latest revision in that order.
+	var revInt int
+	if v, ok := d.GetOk("revision"); ok {
+		revInt, err = strconv.Atoi(v.(string))
+		if err != nil {
+			return fmt.Errorf("[ERROR] dataSourceBundleRead invalid revision %#v: %s", v, err.Error())
+		}
+	} else if env, ok := d.GetOk("env"); ok {
+		if resourcePath == proxiesPath {
+			revInt, err = getDeployedRevision(client, name, env.(string))
+		} else {
+			revInt, err = getDeployedSharedFlowRevision(client, name, env.(string))
+		}
+	} else {
+		if resourcePath == proxiesPath {
+			revInt, err = getLatestRevision(client, name)
+		} else {
+			revInt, err = getLatestSharedFlowRevision(client, name)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("[ERROR] dataSourceBundleRead error getting revision: %s", err.Error())
+	}
+	rev := apigee.Revision(revInt)
+
+	bundle, err := exportRevision(client, resourcePath, name, rev)
+	if err != nil {
+		log.Printf("[ERROR] dataSourceBundleRead error exporting revision %d of %s: %s", rev, name, err.Error())
+		return fmt.Errorf("[ERROR] dataSourceBundleRead error exporting revision %d of %s: %s", rev, name, err.Error())
+	}
+
+	if err := writeBundle(d, bundle); err != nil {
+		return fmt.Errorf("[ERROR] dataSourceBundleRead %s", err.Error())
+	}
+
+	d.SetId(fmt.Sprintf("%s_%d", name, rev))
+	d.Set("revision", rev.String())
+
+	log.Printf("[DEBUG] dataSourceBundleRead exported revision %d of %s to %s", rev, name, d.Get("output_path").(string))
+	return nil
+}
@@ -0,0 +1,9 @@
+package apigee
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceSharedFlowBundle() *schema.Resource {
+	return dataSourceBundle(sharedFlowsPath, "shared_flow_name")
+}
@@ -0,0 +1,40 @@
+package apigee
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccSharedFlowBundleDataSource_Revision(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccCheckSharedFlowBundleDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBundleFileExists("data.apigee_shared_flow_bundle.foo_bundle"),
+					resource.TestCheckResourceAttr(
+						"data.apigee_shared_flow_bundle.foo_bundle", "revision", "1"),
+					resource.TestCheckResourceAttrSet(
+						"data.apigee_shared_flow_bundle.foo_bundle", "output_sha256"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckSharedFlowBundleDataSourceConfig = `
+resource "apigee_shared_flow" "foo_shared_flow" {
+   name  		= "foo_shared_flow_bundle_terraformed"
+   bundle       = "test-fixtures/helloworld_shared_flow.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_shared_flow.zip")}"
+}
+
+data "apigee_shared_flow_bundle" "foo_bundle" {
+   shared_flow_name = "${apigee_shared_flow.foo_shared_flow.name}"
+   revision         = "${apigee_shared_flow.foo_shared_flow.revision}"
+   output_path      = "test-fixtures/exported/foo_shared_flow_bundle_terraformed.zip"
+}
+`
@@ -0,0 +1,74 @@
+package apigee
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+// withDeadlines makes every request a resource sends while it is created, updated or deleted fail once the timeout of
+// that operation has passed.  Retries, throttling and deployment delays count against the timeout as well.
+func withDeadlines(resources map[string]*schema.Resource) map[string]*schema.Resource {
+
+	for _, r := range resources {
+		r.Create = withDeadline(r.Create, schema.TimeoutCreate)
+		r.Update = withDeadline(r.Update, schema.TimeoutUpdate)
+		r.Delete = withDeadline(r.Delete, schema.TimeoutDelete)
+	}
+
+	return resources
+}
+
+func withDeadline(operation func(*schema.ResourceData, interface{}) error, timeout string) func(*schema.ResourceData, interface{}) error {
+
+	if operation == nil {
+		return nil
+	}
+
+	return func(d *schema.ResourceData, meta interface{}) error {
+		return operation(d, meta.(*apigeeClients).within(d.Timeout(timeout)))
+	}
+}
+
+// within returns clients like c whose requests fail once timeout has passed from now.  go-apigee-edge takes no
+// context so the deadline is set on every request by deadlineTransport.
+func (c *apigeeClients) within(timeout time.Duration) *apigeeClients {
+	return &apigeeClients{
+		config:    c.config,
+		transport: &deadlineTransport{deadline: time.Now().Add(timeout), timeout: timeout, base: c.transport},
+		clients:   map[string]*apigee.EdgeClient{},
+	}
+}
+
+type deadlineTransport struct {
+	deadline time.Time
+	timeout  time.Duration
+	base     http.RoundTripper
+}
+
+func (t *deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	ctx, cancel := context.WithDeadline(req.Context(), t.deadline)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timeout of %s exceeded: %s", t.timeout, err.Error())
+		}
+		return nil, err
+	}
+
+	// Failed calls are read by errorBodyTransport already and keep their body to be classified.
+	if _, ok := resp.Body.(*errorBody); ok {
+		cancel()
+		return resp, nil
+	}
+
+	// The deadline holds until the response has been read.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: cancel}
+	return resp, nil
+}
@@ -0,0 +1,93 @@
+package apigee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestDeadline(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	fake.latency = 500 * time.Millisecond
+	config := Config{BaseURI: server.URL, Org: "test-org", AccessToken: "token", MaxRetries: 3, RetryMinWait: time.Second}
+	clients, err := config.Clients()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	start := time.Now()
+	_, _, err = clients.within(100 * time.Millisecond).defaultClient().Proxies.Get("helloworld")
+	if err == nil || !strings.Contains(err.Error(), "timeout of 100ms exceeded") {
+		t.Fatalf("expected the request to fail once the timeout passed, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Fatalf("expected the request to be abandoned at the deadline, it took %s", elapsed)
+	}
+
+	_, _, err = clients.within(time.Minute).defaultClient().Proxies.Get("helloworld")
+	if !isNotFound(err) {
+		t.Fatalf("expected requests within the timeout to be answered, got: %v", err)
+	}
+	if e := asAPIError(err); e.Code == "" {
+		t.Fatalf("expected the error code to be kept, got: %#v", e)
+	}
+}
+
+func TestDeadlineTokenRefresh(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	clients, err := newFakeOAuthClientConfig(server, "secret").Clients()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	start := time.Now()
+	_, _, err = clients.within(100 * time.Millisecond).defaultClient().Proxies.Get("helloworld")
+	if err == nil || !strings.Contains(err.Error(), "timeout of 100ms exceeded") {
+		t.Fatalf("expected the token request to fail once the timeout passed, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 800*time.Millisecond {
+		t.Fatalf("expected the token request to be abandoned at the deadline, it took %s", elapsed)
+	}
+}
+
+func TestDeadlineResourceTimeout(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	fake.latency = 1500 * time.Millisecond
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fakeEdgeProvider(server, "") + testDeadlineConfig,
+				ExpectError: regexp.MustCompile("timeout of 1s exceeded"),
+			},
+		},
+	})
+}
+
+const testDeadlineConfig = `
+resource "apigee_target_server" "foo" {
+   name    = "foo_target_server"
+   host    = "somehost.thatexists.com"
+   env     = "test"
+   enabled = true
+   port    = 8080
+
+   timeouts {
+      create = "1s"
+   }
+}
+`
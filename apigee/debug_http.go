@@ -0,0 +1,92 @@
+package apigee
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxLoggedBody is how much of a body is logged, bundles and exports are summarized instead.
+const maxLoggedBody = 64 * 1024
+
+// debugTransport logs every management API request and response with their secrets redacted.  The [DEBUG] prefix
+// keeps the lines out of the log unless TF_LOG is DEBUG or more verbose.
+type debugTransport struct {
+	base http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	target := redactURL(req.URL)
+	body, err := logBody(req.Header.Get("Content-Type"), req.ContentLength, req.Body, req.URL.Path, func(data []byte) {
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] apigee request: %s %s%s", req.Method, target, body)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		log.Printf("[DEBUG] apigee response: %s %s failed after %s: %s", req.Method, target, latency, err.Error())
+		return nil, err
+	}
+
+	body, err = logBody(resp.Header.Get("Content-Type"), resp.ContentLength, resp.Body, req.URL.Path, func(data []byte) {
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] apigee response: %s %s %s in %s%s", req.Method, target, resp.Status, latency, body)
+
+	return resp, nil
+}
+
+// logBody returns how a body is logged.  Textual bodies are read, handed back through replace and redacted, others
+// are only summarized so that bundles are neither buffered nor dumped in the log.
+func logBody(contentType string, length int64, body io.ReadCloser, path string, replace func([]byte)) (string, error) {
+
+	if body == nil || body == http.NoBody {
+		return "", nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !isTextual(mediaType) || length > maxLoggedBody {
+		return fmt.Sprintf("\n[%s body of %d bytes]", mediaType, length), nil
+	}
+
+	data, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return "", err
+	}
+	replace(data)
+
+	if mediaType == "application/x-www-form-urlencoded" {
+		return "\n" + redactForm(string(data)), nil
+	}
+	redactedData := redactJSON(data, strings.Contains(path, "/keyvaluemaps"))
+	if len(redactedData) > maxLoggedBody {
+		redactedData = redactedData[:maxLoggedBody]
+	}
+
+	return "\n" + string(redactedData), nil
+}
+
+func isTextual(mediaType string) bool {
+	return mediaType == "" ||
+		mediaType == "application/json" ||
+		mediaType == "application/x-www-form-urlencoded" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasPrefix(mediaType, "text/")
+}
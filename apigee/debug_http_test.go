@@ -0,0 +1,94 @@
+package apigee
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zambien/go-apigee-edge"
+)
+
+// captureLog returns everything logged while f runs.
+func captureLog(f func()) string {
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	f()
+	return buf.String()
+}
+
+func TestDebugHTTPRedactsSecrets(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"name":"helloworld","attributes":[{"name":"client_secret","value":"attribute-secret"},{"name":"team","value":"payments"}],`+
+			`"credentials":[{"consumerKey":"the-key","consumerSecret":"consumer-secret"}]}`)
+	}))
+	defer server.Close()
+
+	config := Config{BaseURI: server.URL, Org: "test-org", AccessToken: "token", DebugHTTP: true}
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var app *apigee.DeveloperApp
+	logged := captureLog(func() {
+		app, _, err = client.DeveloperApps.Create("someone@example.com", apigee.DeveloperApp{
+			Name:        "helloworld",
+			Credentials: []apigee.Credential{{ConsumerSecret: "request-secret"}},
+		})
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(app.Credentials) != 1 || app.Credentials[0].ConsumerSecret != "consumer-secret" {
+		t.Fatalf("expected the response to reach the client untouched, got %+v", app)
+	}
+	for _, secret := range []string{"request-secret", "consumer-secret", "attribute-secret"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected %q to be redacted, log:\n%s", secret, logged)
+		}
+	}
+	for _, expected := range []string{"POST " + server.URL + "/v1/o/test-org/developers/someone@example.com/apps", "200 OK", "the-key", "payments"} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("expected the log to contain %q, log:\n%s", expected, logged)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+
+	kvm := string(redactJSON([]byte(`{"name":"settings","entry":[{"name":"endpoint","value":"kvm-secret"}]}`), true))
+	if strings.Contains(kvm, "kvm-secret") || !strings.Contains(kvm, "endpoint") {
+		t.Errorf("expected KVM values to be redacted, got %s", kvm)
+	}
+
+	form := redactForm("grant_type=password&username=someone&password=hunter2&refresh_token=abc")
+	if strings.Contains(form, "hunter2") || strings.Contains(form, "abc") || !strings.Contains(form, "username=someone") {
+		t.Errorf("expected form secrets to be redacted, got %s", form)
+	}
+
+	u, _ := url.Parse("https://login.apigee.com/oauth/token?mfa_token=123456")
+	if redactedURL := redactURL(u); strings.Contains(redactedURL, "123456") {
+		t.Errorf("expected query secrets to be redacted, got %s", redactedURL)
+	}
+
+	object := redactedObject(apigee.CompanyApp{Name: "helloworld", Credentials: []apigee.Credential{{ConsumerKey: "the-key", ConsumerSecret: "consumer-secret"}}})
+	if strings.Contains(object, "consumer-secret") || !strings.Contains(object, "the-key") {
+		t.Errorf("expected consumer secrets to be redacted, got %s", object)
+	}
+
+	if notJSON := string(redactJSON([]byte("<xml/>"), false)); notJSON != "<xml/>" {
+		t.Errorf("expected bodies that are not JSON to be left alone, got %s", notJSON)
+	}
+}
@@ -0,0 +1,170 @@
+package apigee
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/zambien/go-apigee-edge"
+)
+
+// revisionDeploymentStatus is the deployment state of one revision in one environment.  go-apigee-edge's EdgeServer
+// does not decode the per server error so the status is read here.
+type revisionDeploymentStatus struct {
+	State   string                   `json:"state,omitempty"`
+	Servers []deploymentServerStatus `json:"server,omitempty"`
+}
+
+type deploymentServerStatus struct {
+	Status string   `json:"status,omitempty"`
+	Uuid   string   `json:"uUID,omitempty"`
+	Type   []string `json:"type,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func getRevisionDeploymentStatus(client *apigee.EdgeClient, resourcePath string, name string, env string, rev apigee.Revision) (*revisionDeploymentStatus, error) {
+
+	if isApigeeX(client) {
+		return getXRevisionDeploymentStatus(client, resourcePath, name, env, rev)
+	}
+
+	statusPath := path.Join("environments", env, resourcePath, name, "revisions", rev.String(), "deployments")
+	req, err := client.NewRequest("GET", statusPath, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	status := revisionDeploymentStatus{}
+	if _, err := client.Do(req, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// xRevisionDeployment is the Apigee X (and hybrid) deployment state of a revision.  Instances (X) and pods (hybrid)
+// take the place of Edge's message processors.
+type xRevisionDeployment struct {
+	State  string `json:"state,omitempty"`
+	Errors []struct {
+		Message string `json:"message,omitempty"`
+	} `json:"errors,omitempty"`
+	Instances []struct {
+		Instance          string `json:"instance,omitempty"`
+		DeployedRevisions []struct {
+			Revision   string `json:"revision,omitempty"`
+			Percentage int    `json:"percentage,omitempty"`
+		} `json:"deployedRevisions,omitempty"`
+	} `json:"instances,omitempty"`
+	PodStatuses []struct {
+		PodName           string `json:"podName,omitempty"`
+		DeploymentStatus  string `json:"deploymentStatus,omitempty"`
+		StatusCodeDetails string `json:"statusCodeDetails,omitempty"`
+	} `json:"podStatuses,omitempty"`
+}
+
+func getXRevisionDeploymentStatus(client *apigee.EdgeClient, resourcePath string, name string, env string, rev apigee.Revision) (*revisionDeploymentStatus, error) {
+
+	statusPath := path.Join("environments", env, resourcePath, name, "revisions", rev.String(), "deployments")
+	deployment := xRevisionDeployment{}
+	if _, err := xDo(client, "GET", statusPath, nil, nil, &deployment); err != nil {
+		return nil, err
+	}
+
+	status := revisionDeploymentStatus{State: xDeploymentState(deployment.State)}
+
+	for _, e := range deployment.Errors {
+		status.Servers = append(status.Servers, deploymentServerStatus{Uuid: env, Type: []string{"deployment"}, Status: "error", Error: e.Message})
+	}
+	if status.State == "error" && len(deployment.Errors) == 0 {
+		status.Servers = append(status.Servers, deploymentServerStatus{Uuid: env, Type: []string{"deployment"}, Status: "error"})
+	}
+	for _, instance := range deployment.Instances {
+		server := deploymentServerStatus{Uuid: instance.Instance, Type: []string{"instance"}, Status: "progressing"}
+		for _, deployed := range instance.DeployedRevisions {
+			if deployed.Revision == rev.String() && deployed.Percentage == 100 {
+				server.Status = "deployed"
+			}
+		}
+		status.Servers = append(status.Servers, server)
+	}
+	for _, pod := range deployment.PodStatuses {
+		status.Servers = append(status.Servers, deploymentServerStatus{Uuid: pod.PodName, Type: []string{"pod"}, Status: pod.DeploymentStatus, Error: pod.StatusCodeDetails})
+	}
+
+	return &status, nil
+}
+
+// laggingServers describes every server which does not yet report the revision as deployed.
+func laggingServers(status *revisionDeploymentStatus) []string {
+	return describeServers(status, func(server deploymentServerStatus) bool { return server.Status != "deployed" })
+}
+
+// failedServers describes every server which reports an error deploying the revision.
+func failedServers(status *revisionDeploymentStatus) []string {
+	return describeServers(status, func(server deploymentServerStatus) bool { return server.Status == "error" })
+}
+
+func describeServers(status *revisionDeploymentStatus, include func(deploymentServerStatus) bool) []string {
+
+	described := []string{}
+	for _, server := range status.Servers {
+		if !include(server) {
+			continue
+		}
+		description := fmt.Sprintf("%s (%s): %s", server.Uuid, strings.Join(server.Type, ","), server.Status)
+		if server.Error != "" {
+			description = fmt.Sprintf("%s: %s", description, server.Error)
+		}
+		described = append(described, description)
+	}
+	sort.Strings(described)
+
+	return described
+}
+
+// waitForDeploymentReady polls the deployment status of a revision until every server in the environment reports it
+// as deployed.  Apigee answers a deploy call before all message processors have picked up the revision.  A server
+// reporting an error fails the wait straight away, it does not recover on its own.
+func waitForDeploymentReady(client *apigee.EdgeClient, resourcePath string, name string, env string, rev apigee.Revision, timeout time.Duration) error {
+
+	log.Printf("[DEBUG] waitForDeploymentReady waiting up to %s for revision %d of %s in %s", timeout, rev, name, env)
+
+	lagging, failed := []string{}, []string{}
+	err := resource.Retry(timeout, func() *resource.RetryError {
+		status, err := getRevisionDeploymentStatus(client, resourcePath, name, env, rev)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		failed = failedServers(status)
+		if len(failed) > 0 {
+			return resource.NonRetryableError(fmt.Errorf("revision %d of %s failed to deploy in %s", rev, name, env))
+		}
+
+		lagging = laggingServers(status)
+		if len(lagging) > 0 || status.State != "deployed" {
+			log.Printf("[DEBUG] waitForDeploymentReady revision %d of %s is %s in %s, waiting on: %s", rev, name, status.State, env, strings.Join(lagging, "; "))
+			return resource.RetryableError(fmt.Errorf("revision %d of %s is %s in %s", rev, name, status.State, env))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if len(failed) > 0 {
+			return fmt.Errorf("revision %d of %s failed to deploy in %s: %s", rev, name, env, strings.Join(failed, "; "))
+		}
+		if len(lagging) > 0 {
+			return fmt.Errorf("revision %d of %s is not deployed on all servers in %s: %s", rev, name, env, strings.Join(lagging, "; "))
+		}
+		return fmt.Errorf("error waiting for revision %d of %s to be deployed in %s: %s", rev, name, env, err.Error())
+	}
+
+	log.Printf("[DEBUG] waitForDeploymentReady revision %d of %s is deployed on all servers in %s", rev, name, env)
+	return nil
+}
@@ -0,0 +1,93 @@
+package apigee
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/zambien/go-apigee-edge"
+)
+
+func TestLaggingServers(t *testing.T) {
+	status := &revisionDeploymentStatus{
+		State: "error",
+		Servers: []deploymentServerStatus{
+			{Status: "deployed", Uuid: "mp-1", Type: []string{"message-processor"}},
+			{Status: "error", Uuid: "mp-2", Type: []string{"message-processor"}, Error: "Timed out waiting for deployment"},
+			{Status: "pending", Uuid: "r-1", Type: []string{"router"}},
+		},
+	}
+
+	expected := []string{
+		"mp-2 (message-processor): error: Timed out waiting for deployment",
+		"r-1 (router): pending",
+	}
+	if lagging := laggingServers(status); !reflect.DeepEqual(lagging, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, lagging)
+	}
+
+	status.Servers = status.Servers[:1]
+	if lagging := laggingServers(status); len(lagging) != 0 {
+		t.Fatalf("expected no lagging servers, got %#v", lagging)
+	}
+}
+
+func TestWaitForDeploymentReady(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	fake.deploymentStates = []string{"deploying", "deploying", "deployed"}
+	client := newFakeEdgeClient(t, server)
+
+	if err := waitForDeploymentReady(client, proxiesPath, "helloworld", "test", apigee.Revision(1), time.Minute); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	statusRequests := 0
+	for _, request := range fake.requests {
+		if request == "GET /v1/o/test-org/environments/test/apis/helloworld/revisions/1/deployments" {
+			statusRequests++
+		}
+	}
+	if statusRequests != 3 {
+		t.Fatalf("expected the status to be polled until the revision is deployed, got %d requests", statusRequests)
+	}
+}
+
+func TestWaitForDeploymentReadyTimeout(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	fake.deploymentStates = []string{"deploying"}
+	client := newFakeEdgeClient(t, server)
+
+	err := waitForDeploymentReady(client, proxiesPath, "helloworld", "test", apigee.Revision(1), time.Second)
+	if err == nil {
+		t.Fatal("expected an error when the revision is never deployed")
+	}
+	expected := regexp.MustCompile(`^revision 1 of helloworld is not deployed on all servers in test: mp-2 \(message-processor\): deploying$`)
+	if !expected.MatchString(err.Error()) {
+		t.Fatalf("expected the error to name the lagging servers, got: %s", err.Error())
+	}
+}
+
+func TestWaitForDeploymentReadyError(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	fake.deploymentStates = []string{"deploying", "error"}
+	client := newFakeEdgeClient(t, server)
+
+	start := time.Now()
+	err := waitForDeploymentReady(client, proxiesPath, "helloworld", "test", apigee.Revision(1), time.Minute)
+	if err == nil {
+		t.Fatal("expected an error when a server fails to deploy the revision")
+	}
+	expected := regexp.MustCompile(`^revision 1 of helloworld failed to deploy in test: mp-2 \(message-processor\): error: Call timed out$`)
+	if !expected.MatchString(err.Error()) {
+		t.Fatalf("expected the error to name the failed server, got: %s", err.Error())
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected the wait to stop at the error, it took %s", elapsed)
+	}
+}
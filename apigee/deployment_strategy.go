@@ -0,0 +1,162 @@
+package apigee
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+const (
+	deploymentStrategySeamless      = "seamless"
+	deploymentStrategyUndeployFirst = "undeploy_first"
+	deploymentStrategySideBySide    = "side_by_side"
+)
+
+var deploymentStrategies = []string{deploymentStrategySeamless, deploymentStrategyUndeployFirst, deploymentStrategySideBySide}
+
+// deployRevision deploys rev of a proxy or shared flow to the deployment's env following its strategy, on create and
+// on update alike, and sets previous_revision to the revision that was deployed there before.  Nothing is deployed
+// when rev is deployed already.  seamless deploys rev with override, Edge keeps serving the deployed revision for
+// delay seconds and then retires it.  undeploy_first undeploys the deployed revisions before deploying rev, calls
+// fail in between.  side_by_side deploys rev next to the deployed revisions, which must use other base paths.
+func deployRevision(client *apigee.EdgeClient, d *schema.ResourceData, resourcePath string, name string, rev apigee.Revision, timeout time.Duration) error {
+
+	env := d.Get("env").(string)
+	strategy := deploymentStrategy(d)
+
+	deployed, err := deployedRevisions(client, resourcePath, name, env)
+	if err != nil {
+		return fmt.Errorf("error reading deployments of %s: %s", name, err.Error())
+	}
+	for _, revision := range deployed {
+		if revision == rev {
+			log.Printf("[DEBUG] deployRevision revision %d of %s is deployed to %s already", rev, name, env)
+			setPreviousRevision(d, deployed, rev)
+			return nil
+		}
+	}
+
+	if strategy == deploymentStrategyUndeployFirst {
+		for _, revision := range deployed {
+			if err := undeployRevision(client, resourcePath, name, env, revision); err != nil {
+				return fmt.Errorf("error undeploying revision %d of %s: %s", revision, name, err.Error())
+			}
+		}
+	}
+
+	override := strategy == deploymentStrategySeamless
+	delay := 0
+	if override {
+		//The delay is spent before the call returns so it must fit in the timeout.
+		delay = delayWithinTimeout(d.Get("delay").(int), timeout)
+	}
+
+	log.Printf("[DEBUG] deployRevision deploying revision %d of %s to %s, strategy %s", rev, name, env, strategy)
+	if err := deploy(client, resourcePath, name, env, rev, delay, override, override && len(deployed) > 0); err != nil {
+		return fmt.Errorf("error deploying revision %d of %s: %s", rev, name, err.Error())
+	}
+
+	setPreviousRevision(d, deployed, rev)
+
+	return nil
+}
+
+// deploymentStrategy returns the strategy of a deployment.  The deprecated override = true still deploys seamlessly,
+// override = false is its default and is not told apart from override not being set.
+func deploymentStrategy(d *schema.ResourceData) string {
+
+	if d.Get("override").(bool) {
+		return deploymentStrategySeamless
+	}
+
+	return d.Get("strategy").(string)
+}
+
+// setPreviousRevision sets previous_revision to the last of the deployed revisions other than rev.  When rev was the
+// only one deployed the previous revision is kept.
+func setPreviousRevision(d *schema.ResourceData, deployed []apigee.Revision, rev apigee.Revision) {
+
+	previous, _ := d.GetChange("previous_revision")
+	for _, revision := range deployed {
+		if revision != rev {
+			previous = revision.String()
+		}
+	}
+	d.Set("previous_revision", previous)
+}
+
+// deploymentCustomizeDiff marks previous_revision as changing whenever another revision is to be deployed.
+func deploymentCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+
+	if d.Id() != "" && d.HasChange("revision") {
+		return d.SetNewComputed("previous_revision")
+	}
+
+	return nil
+}
+
+// deployedRevisions returns the revisions of a proxy or shared flow deployed to env.
+func deployedRevisions(client *apigee.EdgeClient, resourcePath string, name string, env string) ([]apigee.Revision, error) {
+
+	var environments []apigee.EnvironmentDeployment
+	if resourcePath == proxiesPath {
+		deployments, _, err := client.Proxies.GetDeployments(name)
+		if err != nil {
+			return nil, err
+		}
+		environments = deployments.Environments
+	} else {
+		deployments, _, err := client.SharedFlows.GetDeployments(name)
+		if err != nil {
+			return nil, err
+		}
+		environments = deployments.Environments
+	}
+
+	revisions := []apigee.Revision{}
+	for _, environment := range environments {
+		if environment.Name == env {
+			for _, revision := range environment.Revision {
+				revisions = append(revisions, revision.Number)
+			}
+		}
+	}
+
+	return revisions, nil
+}
+
+// deploy deploys a revision.  Edge answers a deployment overriding another one differently, redeploy decodes that.
+func deploy(client *apigee.EdgeClient, resourcePath string, name string, env string, rev apigee.Revision, delay int, override bool, redeploy bool) error {
+
+	var err error
+	switch {
+	case resourcePath == proxiesPath && redeploy:
+		_, _, err = client.Proxies.ReDeploy(name, env, rev, delay, override)
+	case resourcePath == proxiesPath:
+		_, _, err = client.Proxies.Deploy(name, env, rev, delay, override)
+	case redeploy:
+		_, _, err = client.SharedFlows.ReDeploy(name, env, rev, delay, override)
+	default:
+		_, _, err = client.SharedFlows.Deploy(name, env, rev, delay, override)
+	}
+
+	return err
+}
+
+func undeployRevision(client *apigee.EdgeClient, resourcePath string, name string, env string, rev apigee.Revision) error {
+
+	var err error
+	if resourcePath == proxiesPath {
+		_, _, err = client.Proxies.Undeploy(name, env, rev)
+	} else {
+		_, _, err = client.SharedFlows.Undeploy(name, env, rev)
+	}
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package apigee
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zambien/go-apigee-edge"
+)
+
+// apiError is what a failed management API call tells about itself.  Edge answers with
+// {"code": "messaging.config.beans.ApplicationDoesNotExist", "message": "..."} and Apigee X with
+// {"error": {"code": 404, "message": "...", "status": "NOT_FOUND"}}, Code holds the Edge code or the X status.
+type apiError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// isNotFound is true when the object asked for does not exist.  Only a 404 counts: a 400 saying that something the
+// request refers to does not exist, a product naming a missing proxy, means the request is wrong, not that it is gone.
+func isNotFound(err error) bool {
+	e := asAPIError(err)
+	return e != nil && e.StatusCode == http.StatusNotFound
+}
+
+// isConflict is true when a change clashes with deployments that are still being undeployed, or with a concurrent
+// change, and goes through when tried again a little later.  Other conflicts and failed preconditions, a proxy used by
+// a product or a base path taken by another proxy, last until someone acts on them and are not matched.
+func isConflict(err error) bool {
+	e := asAPIError(err)
+	if e == nil {
+		return false
+	}
+	if e.Code == "FAILED_PRECONDITION" {
+		// Apigee X tells a pending undeploy apart by the message only.
+		return strings.Contains(strings.ToLower(e.Message), "has deployments")
+	}
+	return e.Code == "ABORTED" || e.codeContains("hasdeployments")
+}
+
+// isRateLimited is true when the call was turned away because too many were made.
+func isRateLimited(err error) bool {
+	e := asAPIError(err)
+	return e != nil && (e.StatusCode == http.StatusTooManyRequests || e.codeContains("resourceexhausted", "ratelimit"))
+}
+
+// asAPIError returns the apiError behind an error returned by go-apigee-edge, or nil for any other error.
+func asAPIError(err error) *apiError {
+
+	var errorResponse *apigee.ErrorResponse
+	if !errors.As(err, &errorResponse) || errorResponse.Response == nil {
+		return nil
+	}
+	if body, ok := errorResponse.Response.Body.(*errorBody); ok {
+		return body.apiError
+	}
+
+	return &apiError{StatusCode: errorResponse.Response.StatusCode, Message: errorResponse.Message}
+}
+
+// codeContains matches the code ignoring case and separators so that ApplicationDoesNotExist, app_doesnot_exist
+// and NOT_FOUND all read the same.
+func (e *apiError) codeContains(words ...string) bool {
+
+	code := strings.ToLower(e.Code)
+	code = strings.NewReplacer("_", "", "-", "", " ", "").Replace(code)
+	for _, word := range words {
+		if strings.Contains(code, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseAPIError reads the body of a failed call in either the Edge or the Apigee X format.
+func parseAPIError(statusCode int, data []byte) *apiError {
+
+	apiErr := &apiError{StatusCode: statusCode}
+
+	var body struct {
+		Code    interface{} `json:"code"`
+		Message string      `json:"message"`
+		Error   *struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		apiErr.Message = strings.TrimSpace(string(data))
+		return apiErr
+	}
+
+	switch code := body.Code.(type) {
+	case string:
+		apiErr.Code = code
+	case float64:
+		apiErr.Code = strconv.Itoa(int(code))
+	}
+	apiErr.Message = body.Message
+	if body.Error != nil {
+		apiErr.Code = body.Error.Status
+		apiErr.Message = body.Error.Message
+	}
+
+	return apiErr
+}
+
+// errorBodyTransport keeps what failed calls answered so that the error go-apigee-edge makes of them can be
+// classified.  go-apigee-edge reads nothing but "message" from an error body and gives up on bodies that are not
+// JSON, so it is handed the message alone.
+type errorBodyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *errorBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || (resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		return resp, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	apiErr := parseAPIError(resp.StatusCode, data)
+	message, _ := json.Marshal(map[string]string{"message": apiErr.Message})
+	resp.Body = &errorBody{Reader: bytes.NewReader(message), apiError: apiErr}
+	resp.ContentLength = int64(len(message))
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
+
+type errorBody struct {
+	*bytes.Reader
+	apiError *apiError
+}
+
+func (b *errorBody) Close() error {
+	return nil
+}
@@ -0,0 +1,146 @@
+package apigee
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorClassification(t *testing.T) {
+
+	cases := []struct {
+		name        string
+		status      int
+		body        string
+		message     string
+		notFound    bool
+		conflict    bool
+		rateLimited bool
+	}{
+		{
+			name:     "edge not found",
+			status:   http.StatusNotFound,
+			body:     `{"code":"messaging.config.beans.ApplicationDoesNotExist","message":"APIProxy named helloworld does not exist in organization test-org","contexts":[]}`,
+			message:  "APIProxy named helloworld does not exist",
+			notFound: true,
+		},
+		{
+			name:    "edge missing reference",
+			status:  http.StatusBadRequest,
+			body:    `{"code":"keymanagement.service.apiresource_doesnot_exist","message":"API Product references proxy helloworld which does not exist"}`,
+			message: "which does not exist",
+		},
+		{
+			name:    "edge already deployed",
+			status:  http.StatusBadRequest,
+			body:    `{"code":"distribution.RevisionAlreadyDeployed","message":"Revision 1 of helloworld is already deployed in environment test"}`,
+			message: "already deployed",
+		},
+		{
+			name:    "edge base path conflict",
+			status:  http.StatusBadRequest,
+			body:    `{"code":"messaging.config.beans.BasePathConflict","message":"Path /hello conflicts with existing deployment path"}`,
+			message: "conflicts with existing deployment path",
+		},
+		{
+			name:     "edge undeploy pending",
+			status:   http.StatusBadRequest,
+			body:     `{"code":"messaging.config.beans.ApplicationHasDeployments","message":"Undeploy the ApiProxy and try again"}`,
+			message:  "Undeploy the ApiProxy and try again",
+			conflict: true,
+		},
+		{
+			name:    "edge conflict",
+			status:  http.StatusConflict,
+			body:    `{"code":"keymanagement.service.ApiProductReferencesProxy","message":"APIProxy helloworld is used by API products [foo]"}`,
+			message: "is used by API products",
+		},
+		{
+			name:     "apigee x undeploy pending",
+			status:   http.StatusConflict,
+			body:     `{"error":{"code":409,"message":"proxy helloworld has deployments","status":"FAILED_PRECONDITION"}}`,
+			message:  "proxy helloworld has deployments",
+			conflict: true,
+		},
+		{
+			name:    "apigee x failed precondition",
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":400,"message":"proxy helloworld is used by API products","status":"FAILED_PRECONDITION"}}`,
+			message: "is used by API products",
+		},
+		{
+			name:     "apigee x concurrent change",
+			status:   http.StatusConflict,
+			body:     `{"error":{"code":409,"message":"the proxy was changed concurrently","status":"ABORTED"}}`,
+			message:  "changed concurrently",
+			conflict: true,
+		},
+		{
+			name:     "apigee x not found",
+			status:   http.StatusNotFound,
+			body:     `{"error":{"code":404,"message":"organizations/test-org/apis/helloworld not found","status":"NOT_FOUND"}}`,
+			message:  "not found",
+			notFound: true,
+		},
+		{
+			name:        "rate limited",
+			status:      http.StatusTooManyRequests,
+			body:        `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+			message:     "Quota exceeded",
+			rateLimited: true,
+		},
+		{
+			name:    "not json",
+			status:  http.StatusBadGateway,
+			body:    `<html><body>Bad Gateway</body></html>`,
+			message: "Bad Gateway",
+		},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(c.status)
+			w.Write([]byte(c.body))
+		}))
+
+		config := Config{BaseURI: server.URL, Org: "test-org", AccessToken: "token"}
+		client, err := config.Client()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, _, err = client.Proxies.Get("helloworld")
+		server.Close()
+
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+			continue
+		}
+		if apiErr := asAPIError(err); apiErr == nil || apiErr.StatusCode != c.status {
+			t.Errorf("%s: expected an API error with status %d, got %#v", c.name, c.status, apiErr)
+		}
+		if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: expected the error to contain %q, got %q", c.name, c.message, err.Error())
+		}
+		if isNotFound(err) != c.notFound {
+			t.Errorf("%s: expected isNotFound to be %t", c.name, c.notFound)
+		}
+		if isConflict(err) != c.conflict {
+			t.Errorf("%s: expected isConflict to be %t", c.name, c.conflict)
+		}
+		if isRateLimited(err) != c.rateLimited {
+			t.Errorf("%s: expected isRateLimited to be %t", c.name, c.rateLimited)
+		}
+	}
+}
+
+func TestErrorClassificationOtherErrors(t *testing.T) {
+
+	err := errors.New("GET https://api.enterprise.apigee.com/v1/o/test-org/apis/helloworld: 404 not found")
+	if isNotFound(err) || isConflict(err) || isRateLimited(err) {
+		t.Fatal("expected errors that do not come from the management API not to be classified")
+	}
+}
@@ -0,0 +1,240 @@
+package apigee
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// serveBundles answers the requests for proxies and shared flows, their revisions and deployments.  It reports
+// whether the request was one of them.
+func (f *fakeEdge) serveBundles(w http.ResponseWriter, r *http.Request, path string) bool {
+
+	segments := strings.Split(path, "/")
+	route := r.Method + " " + strings.Join(placeholders(segments), "/")
+
+	switch route {
+	case "POST apis", "POST sharedflows":
+		f.importBundle(w, r, segments[0]+"/"+r.URL.Query().Get("name"))
+	case "GET apis/*", "GET sharedflows/*":
+		f.getBundle(w, path)
+	case "DELETE apis/*", "DELETE sharedflows/*":
+		f.deleteBundle(w, path)
+	case "GET apis/*/revisions/*", "GET sharedflows/*/revisions/*":
+		f.exportRevision(w, r, segments[0]+"/"+segments[1], segments[3])
+	case "DELETE apis/*/revisions/*", "DELETE sharedflows/*/revisions/*":
+		f.deleteRevision(w, segments[0]+"/"+segments[1], segments[3])
+	case "GET apis/*/deployments", "GET sharedflows/*/deployments":
+		f.getDeployments(w, segments[0]+"/"+segments[1])
+	case "POST apis/*/revisions/*/deployments":
+		if r.URL.Query().Get("action") != "undeploy" {
+			return false
+		}
+		f.undeploy(w, "apis/"+segments[1], r.URL.Query().Get("env"), segments[3])
+	case "POST environments/*/apis/*/revisions/*/deployments", "POST environments/*/sharedflows/*/revisions/*/deployments":
+		f.deploy(w, segments[2]+"/"+segments[3], segments[1], segments[5], r.URL.Query().Get("override") == "true")
+	case "DELETE environments/*/sharedflows/*/revisions/*/deployments":
+		f.undeploy(w, "sharedflows/"+segments[3], segments[1], segments[5])
+	case "GET environments/*/apis/*/revisions/*/deployments", "GET environments/*/sharedflows/*/revisions/*/deployments":
+		f.deploymentStatus(w)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (f *fakeEdge) importBundle(w http.ResponseWriter, r *http.Request, key string) {
+
+	bundle, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		f.error(w, http.StatusBadRequest, "fake.InvalidBundle")
+		return
+	}
+	f.bundles[key] = append(f.bundles[key], bundle)
+
+	f.write(w, map[string]interface{}{"name": strings.Split(key, "/")[1], "revision": strconv.Itoa(len(f.bundles[key]))})
+}
+
+// revisions returns the revisions of a proxy or shared flow that were not deleted.
+func (f *fakeEdge) revisions(key string) []string {
+
+	revisions := []string{}
+	for i, bundle := range f.bundles[key] {
+		if bundle != nil {
+			revisions = append(revisions, strconv.Itoa(i+1))
+		}
+	}
+
+	return revisions
+}
+
+func (f *fakeEdge) getBundle(w http.ResponseWriter, key string) {
+
+	revisions := f.revisions(key)
+	if len(revisions) == 0 {
+		f.error(w, http.StatusNotFound, "fake.ApplicationDoesNotExist")
+		return
+	}
+	f.write(w, map[string]interface{}{"name": strings.Split(key, "/")[1], "revision": revisions})
+}
+
+func (f *fakeEdge) deleteBundle(w http.ResponseWriter, key string) {
+
+	if len(f.revisions(key)) == 0 {
+		f.error(w, http.StatusNotFound, "fake.ApplicationDoesNotExist")
+		return
+	}
+	if len(f.deployments[key]) > 0 {
+		f.error(w, http.StatusBadRequest, "fake.ApplicationHasDeployments")
+		return
+	}
+	delete(f.bundles, key)
+	f.write(w, map[string]interface{}{"name": strings.Split(key, "/")[1]})
+}
+
+// revision returns the bundle of a revision, nil when there is no such revision.
+func (f *fakeEdge) revision(key string, rev string) []byte {
+
+	i, err := strconv.Atoi(rev)
+	if err != nil || i < 1 || i > len(f.bundles[key]) {
+		return nil
+	}
+
+	return f.bundles[key][i-1]
+}
+
+func (f *fakeEdge) exportRevision(w http.ResponseWriter, r *http.Request, key string, rev string) {
+
+	bundle := f.revision(key, rev)
+	if bundle == nil {
+		f.error(w, http.StatusNotFound, "fake.RevisionDoesNotExist")
+		return
+	}
+	if r.URL.Query().Get("format") != "bundle" {
+		f.write(w, map[string]interface{}{"name": strings.Split(key, "/")[1], "revision": rev})
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(bundle)
+}
+
+func (f *fakeEdge) deleteRevision(w http.ResponseWriter, key string, rev string) {
+
+	if f.revision(key, rev) == nil {
+		f.error(w, http.StatusNotFound, "fake.RevisionDoesNotExist")
+		return
+	}
+	for _, deployed := range f.deployments[key] {
+		for _, deployed := range deployed {
+			if strconv.Itoa(deployed) == rev {
+				f.error(w, http.StatusBadRequest, "fake.RevisionHasDeployments")
+				return
+			}
+		}
+	}
+	i, _ := strconv.Atoi(rev)
+	f.bundles[key][i-1] = nil
+	f.write(w, map[string]interface{}{"name": strings.Split(key, "/")[1], "revision": rev})
+}
+
+func (f *fakeEdge) getDeployments(w http.ResponseWriter, key string) {
+
+	if len(f.revisions(key)) == 0 {
+		f.error(w, http.StatusNotFound, "fake.ApplicationDoesNotExist")
+		return
+	}
+	environments := []interface{}{}
+	for env, deployed := range f.deployments[key] {
+		revisions := []interface{}{}
+		for _, rev := range deployed {
+			revisions = append(revisions, map[string]interface{}{"name": strconv.Itoa(rev), "state": "deployed"})
+		}
+		environments = append(environments, map[string]interface{}{"name": env, "revision": revisions})
+	}
+	f.write(w, map[string]interface{}{"name": strings.Split(key, "/")[1], "organization": "test-org", "environment": environments})
+}
+
+// deploy deploys a revision.  With override it replaces the revisions deployed to the environment as Edge does,
+// without it the revision is deployed next to them as if their base paths differed.
+func (f *fakeEdge) deploy(w http.ResponseWriter, key string, env string, rev string, override bool) {
+
+	if f.revision(key, rev) == nil {
+		f.error(w, http.StatusNotFound, "fake.RevisionDoesNotExist")
+		return
+	}
+	if f.deployments[key] == nil {
+		f.deployments[key] = map[string][]int{}
+	}
+	i, _ := strconv.Atoi(rev)
+	if override && len(f.deployments[key][env]) > 0 {
+		// Edge answers a deployment overriding another one with every environment's deployment.
+		f.deployments[key][env] = []int{i}
+		f.write(w, map[string]interface{}{"environment": []interface{}{f.deployment(key, env, rev)}, "organization": "test-org"})
+		return
+	}
+	f.deployments[key][env] = append(f.deployments[key][env], i)
+
+	f.write(w, f.deployment(key, env, rev))
+}
+
+func (f *fakeEdge) undeploy(w http.ResponseWriter, key string, env string, rev string) {
+
+	remaining := []int{}
+	for _, deployed := range f.deployments[key][env] {
+		if strconv.Itoa(deployed) != rev {
+			remaining = append(remaining, deployed)
+		}
+	}
+	if len(remaining) == len(f.deployments[key][env]) {
+		f.error(w, http.StatusNotFound, "fake.RevisionNotDeployed")
+		return
+	}
+	if len(remaining) == 0 {
+		delete(f.deployments[key], env)
+	} else {
+		f.deployments[key][env] = remaining
+	}
+
+	f.write(w, f.deployment(key, env, rev))
+}
+
+func (f *fakeEdge) deployment(key string, env string, rev string) map[string]interface{} {
+	return map[string]interface{}{
+		"aPIProxy":     strings.Split(key, "/")[1],
+		"environment":  env,
+		"revision":     rev,
+		"organization": "test-org",
+		"state":        "deployed",
+	}
+}
+
+// deploymentStatus answers a request for the deployment status of a revision, see deploymentStates.
+func (f *fakeEdge) deploymentStatus(w http.ResponseWriter) {
+
+	servers := []interface{}{map[string]interface{}{"status": "deployed", "uUID": "mp-1", "type": []string{"message-processor"}}}
+	state := "deployed"
+	if len(f.deploymentStates) > 0 {
+		state = f.deploymentStates[0]
+		if len(f.deploymentStates) > 1 {
+			f.deploymentStates = f.deploymentStates[1:]
+		}
+		server := map[string]interface{}{"status": state, "uUID": "mp-2", "type": []string{"message-processor"}}
+		if state == "error" {
+			server["error"] = "Call timed out"
+		}
+		servers = append(servers, server)
+	}
+
+	f.write(w, map[string]interface{}{"state": state, "server": servers})
+}
+
+// deployed returns the revisions of a proxy or shared flow deployed to env.
+func (f *fakeEdge) deployed(key string, env string) []int {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]int{}, f.deployments[key][env]...)
+}
@@ -0,0 +1,340 @@
+package apigee
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zambien/go-apigee-edge"
+)
+
+// fakeEdge is an in memory Apigee Edge management API for the entities that are plain JSON documents: products,
+// developers, developer apps, companies, company apps and target servers.  Entities are kept by their path below the
+// organization, e.g. "developers/someone@example.com/apps/helloworld".  Proxies and shared flows, their revisions and
+// deployments are kept apart, see serveBundles.
+type fakeEdge struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	entities map[string]map[string]interface{}
+	requests []string
+
+	bundles     map[string][][]byte         // "apis/helloworld" -> revisions, nil once deleted
+	deployments map[string]map[string][]int // "apis/helloworld" -> env -> revisions
+
+	// deploymentStates are the states a second message processor reports for a deployed revision, one per status
+	// request and the last one repeating.  Revisions are deployed on every message processor when there are none.
+	deploymentStates []string
+
+	// latency delays every answer.
+	latency time.Duration
+
+	// refuse answers "DELETE apis/helloworld" and the like with a 409 and the given error code.
+	refuse map[string]string
+}
+
+func newFakeEdge(t *testing.T) (*fakeEdge, *httptest.Server) {
+
+	fake := &fakeEdge{
+		t:           t,
+		entities:    map[string]map[string]interface{}{},
+		bundles:     map[string][][]byte{},
+		deployments: map[string]map[string][]int{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+// newFakeEdgeClient returns a client of the provider's organization at a fakeEdge.
+func newFakeEdgeClient(t *testing.T, server *httptest.Server) *apigee.EdgeClient {
+
+	config := Config{BaseURI: server.URL, Org: "test-org", AccessToken: "token"}
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return client
+}
+
+// fakeEdgeProvider is the provider block of a configuration run against a fakeEdge, extra is added to it.
+func fakeEdgeProvider(server *httptest.Server, extra string) string {
+	return fmt.Sprintf(`
+provider "apigee" {
+   base_uri     = "%s"
+   org          = "test-org"
+   access_token = "token"
+   max_retries  = 0
+   %s
+}
+`, server.URL, extra)
+}
+
+func (f *fakeEdge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	time.Sleep(f.latency)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	prefix := "/v1/o/test-org/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		f.error(w, http.StatusNotFound, "organizations.OrganizationDoesNotExist")
+		return
+	}
+	path := f.resolve(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, prefix), "/"))
+	if code, ok := f.refuse[r.Method+" "+path]; ok {
+		f.error(w, http.StatusConflict, code)
+		return
+	}
+	if f.serveBundles(w, r, path) {
+		return
+	}
+	collection := len(strings.Split(path, "/"))%2 == 1
+
+	switch {
+	case collection && r.Method == "GET":
+		f.list(w, path)
+	case collection && r.Method == "POST":
+		f.create(w, r, path)
+	case !collection && r.Method == "GET":
+		f.get(w, path)
+	case !collection && r.Method == "PUT":
+		f.update(w, r, path)
+	case !collection && r.Method == "DELETE":
+		f.delete(w, path)
+	default:
+		f.t.Errorf("fakeEdge unexpected request %s %s", r.Method, r.URL)
+		f.error(w, http.StatusNotImplemented, "fake.NotImplemented")
+	}
+}
+
+// resolve returns path with a developer ID replaced by the developer's email, Edge accepts either.
+func (f *fakeEdge) resolve(path string) string {
+
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 || segments[0] != "developers" {
+		return path
+	}
+	for key, entity := range f.entities {
+		if entity["developerId"] == segments[1] && len(strings.Split(key, "/")) == 2 {
+			segments[1] = strings.TrimPrefix(key, "developers/")
+			break
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func (f *fakeEdge) error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"code":%q,"message":"fake error","contexts":[]}`, code)
+}
+
+func (f *fakeEdge) write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// children returns the names of the entities directly below path.
+func (f *fakeEdge) children(path string) []string {
+
+	names := []string{}
+	for key := range f.entities {
+		if strings.HasPrefix(key, path+"/") && !strings.Contains(strings.TrimPrefix(key, path+"/"), "/") {
+			names = append(names, strings.TrimPrefix(key, path+"/"))
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (f *fakeEdge) list(w http.ResponseWriter, path string) {
+	f.write(w, f.children(path))
+}
+
+func (f *fakeEdge) create(w http.ResponseWriter, r *http.Request, path string) {
+
+	entity := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&entity); err != nil {
+		f.error(w, http.StatusBadRequest, "fake.InvalidJSON")
+		return
+	}
+	key, _ := entity["name"].(string)
+	if email, ok := entity["email"].(string); ok && strings.HasPrefix(path, "developers") && !strings.Contains(path, "/") {
+		key = email
+	}
+	if _, exists := f.entities[path+"/"+key]; exists {
+		f.error(w, http.StatusConflict, "fake.EntityAlreadyExists")
+		return
+	}
+
+	switch {
+	case path == "developers":
+		entity["developerId"] = "developer-" + key
+		entity["status"] = "active"
+	case strings.HasSuffix(path, "/apps"):
+		entity["appId"] = "app-" + key
+		entity["status"] = "approved"
+		if developer, ok := f.entities[strings.TrimSuffix(path, "/apps")]; ok {
+			entity["developerId"] = developer["developerId"]
+		}
+		products := []interface{}{}
+		if names, ok := entity["apiProducts"].([]interface{}); ok {
+			for _, name := range names {
+				products = append(products, map[string]interface{}{"apiproduct": name, "status": "approved"})
+			}
+		}
+		entity["credentials"] = []interface{}{map[string]interface{}{
+			"consumerKey":    "key-" + key,
+			"consumerSecret": "secret-" + key,
+			"apiProducts":    products,
+			"scopes":         entity["scopes"],
+			"issuedAt":       1600000000000,
+			"expiresAt":      -1,
+		}}
+	case path == "companies":
+		entity["status"] = "active"
+	}
+
+	f.entities[path+"/"+key] = entity
+	f.write(w, f.view(path+"/"+key))
+}
+
+// view is an entity as Edge returns it, developers and companies list their apps.
+func (f *fakeEdge) view(path string) map[string]interface{} {
+
+	entity := map[string]interface{}{}
+	for k, v := range f.entities[path] {
+		entity[k] = v
+	}
+	if segments := strings.Split(path, "/"); len(segments) == 2 && (segments[0] == "developers" || segments[0] == "companies") {
+		entity["apps"] = f.children(path + "/apps")
+	}
+
+	return entity
+}
+
+func (f *fakeEdge) get(w http.ResponseWriter, path string) {
+
+	if _, ok := f.entities[path]; !ok {
+		f.error(w, http.StatusNotFound, "fake.EntityDoesNotExist")
+		return
+	}
+	f.write(w, f.view(path))
+}
+
+func (f *fakeEdge) update(w http.ResponseWriter, r *http.Request, path string) {
+
+	existing, ok := f.entities[path]
+	if !ok {
+		f.error(w, http.StatusNotFound, "fake.EntityDoesNotExist")
+		return
+	}
+	entity := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&entity); err != nil {
+		f.error(w, http.StatusBadRequest, "fake.InvalidJSON")
+		return
+	}
+	// Server side fields survive an update.
+	for _, key := range []string{"developerId", "appId", "status", "credentials"} {
+		if v, ok := existing[key]; ok {
+			entity[key] = v
+		}
+	}
+
+	// A developer's email can change, the developer and their apps move with it.
+	if email, ok := entity["email"].(string); ok && strings.HasPrefix(path, "developers/") && len(strings.Split(path, "/")) == 2 && path != "developers/"+email {
+		for key, v := range f.entities {
+			if key == path || strings.HasPrefix(key, path+"/") {
+				delete(f.entities, key)
+				f.entities["developers/"+email+strings.TrimPrefix(key, path)] = v
+			}
+		}
+		path = "developers/" + email
+	}
+
+	f.entities[path] = entity
+	f.write(w, f.view(path))
+}
+
+func (f *fakeEdge) delete(w http.ResponseWriter, path string) {
+
+	entity, ok := f.entities[path]
+	if !ok {
+		f.error(w, http.StatusNotFound, "fake.EntityDoesNotExist")
+		return
+	}
+	for key := range f.entities {
+		if key == path || strings.HasPrefix(key, path+"/") {
+			delete(f.entities, key)
+		}
+	}
+	f.write(w, entity)
+}
+
+// attributes returns the attributes of an entity as a map.
+func (f *fakeEdge) attributes(path string) map[string]string {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := map[string]string{}
+	attributes, _ := f.entities[path]["attributes"].([]interface{})
+	for _, attribute := range attributes {
+		attribute := attribute.(map[string]interface{})
+		result[attribute["name"].(string)], _ = attribute["value"].(string)
+	}
+
+	return result
+}
+
+// setAttribute changes an attribute of an entity behind Terraform's back, the way the developer portal would.
+func (f *fakeEdge) setAttribute(path string, name string, value string) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	attributes, _ := f.entities[path]["attributes"].([]interface{})
+	for _, attribute := range attributes {
+		if attribute := attribute.(map[string]interface{}); attribute["name"] == name {
+			attribute["value"] = value
+			return
+		}
+	}
+	f.entities[path]["attributes"] = append(attributes, map[string]interface{}{"name": name, "value": value})
+}
+
+// seed stores a document the fake cannot create itself, e.g. an environment.
+func (f *fakeEdge) seed(path string, document string) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entity := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(document), &entity); err != nil {
+		f.t.Fatalf("fakeEdge invalid document for %s: %s", path, err.Error())
+	}
+	f.entities[path] = entity
+}
+
+// edit changes an entity behind Terraform's back.
+func (f *fakeEdge) edit(path string, change func(entity map[string]interface{})) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	change(f.entities[path])
+}
@@ -1,10 +1,12 @@
 package apigee
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/zambien/go-apigee-edge"
-	"reflect"
-	"sort"
 )
 
 func flattenStringList(list []string) []interface{} {
@@ -12,7 +14,7 @@ func flattenStringList(list []string) []interface{} {
 	vs := make([]interface{}, 0, len(list))
 
 	for _, v := range list {
-		vs = append(vs, &v)
+		vs = append(vs, v)
 	}
 
 	return vs
@@ -23,6 +25,9 @@ func getStringList(listName string, d *schema.ResourceData) []string {
 	stringList := []string{}
 
 	if attr, ok := d.GetOk(listName); ok {
+		if set, ok := attr.(*schema.Set); ok {
+			attr = set.List()
+		}
 		for _, s := range attr.([]interface{}) {
 			if s != nil {
 				stringList = append(stringList, s.(string))
@@ -89,26 +94,76 @@ func flattenCredentials(in []apigee.Credential) []interface{} {
 	return make([]interface{}, 0)
 }
 
-func arraySortedEqual(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
+// lastDeployedRevision returns the last revision deployed to env.  Like the deployment resources we always take the
+// last one if there are multiple deployments.
+func lastDeployedRevision(environments []apigee.EnvironmentDeployment, env string) (apigee.Revision, bool) {
+
+	found := false
+	var rev apigee.Revision
+
+	for _, environment := range environments {
+		if environment.Name == env {
+			for _, revision := range environment.Revision {
+				rev = revision.Number
+				found = true
+			}
+		}
 	}
 
-	a_copy := make([]string, len(a))
-	b_copy := make([]string, len(b))
+	return rev, found
+}
 
-	copy(a_copy, a)
-	copy(b_copy, b)
+// delayWithinTimeout caps a deployment delay (in seconds) so that it cannot outlast the operation timeout.
+func delayWithinTimeout(delay int, timeout time.Duration) int {
 
-	sort.Strings(a_copy)
-	sort.Strings(b_copy)
+	if max := int(timeout / time.Second); delay > max {
+		return max
+	}
+
+	return delay
+}
 
-	return reflect.DeepEqual(a_copy, b_copy)
+// setImportDefaults sets the attributes of an imported resource that have a default to it.  Apigee does not return
+// them, imports would otherwise plan to change them.
+func setImportDefaults(d *schema.ResourceData, r *schema.Resource) {
+	for key, s := range r.Schema {
+		if s.Default != nil {
+			d.Set(key, s.Default)
+		}
+	}
 }
 
-func updateResourceOnSortedArrayChange(d *schema.ResourceData, key string, newValues []string) {
-	currentValues := getStringList(key, d)
-	if currentValues != nil && !arraySortedEqual(currentValues, newValues) {
-		d.Set(key, newValues)
+// checkForceDestroy refuses to delete a developer or company that still has apps unless force_destroy is set.
+// Deleting them deletes every app they own and its keys, including apps not managed by terraform.
+func checkForceDestroy(d *schema.ResourceData, kind string, name string, apps []string) error {
+
+	if d.Get("force_destroy").(bool) || len(apps) == 0 {
+		return nil
 	}
+
+	return fmt.Errorf("%s %s still has apps %s, deleting it would delete them and their keys.  Delete the apps first or set force_destroy = true", kind, name, strings.Join(apps, ", "))
+}
+
+// checkDeletionProtection refuses to delete a resource with deletion_protection set.  Delete sees the setting of the
+// state, so it has to be turned off by an apply of its own before the resource can be destroyed.
+func checkDeletionProtection(d *schema.ResourceData, description string) error {
+
+	if !d.Get("deletion_protection").(bool) {
+		return nil
+	}
+
+	return fmt.Errorf("%s has deletion_protection set.  Set it to false and apply before destroying it", description)
+}
+
+// onlyDeletionProtectionChanged is true when an update only turns deletion_protection on or off, which Apigee knows
+// nothing about.
+func onlyDeletionProtectionChanged(d *schema.ResourceData, r *schema.Resource) bool {
+
+	for key := range r.Schema {
+		if key != "deletion_protection" && d.HasChange(key) {
+			return false
+		}
+	}
+
+	return d.HasChange("deletion_protection")
 }
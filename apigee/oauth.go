@@ -0,0 +1,293 @@
+package apigee
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOAuthTokenURI = "https://login.apigee.com/oauth/token"
+
+	// Apigee's documented public client used by get_token and the management UI.
+	oauthClientID     = "edgecli"
+	oauthClientSecret = "edgeclisecret"
+
+	// Tokens are refreshed this long before Apigee says they expire.
+	oauthExpiryMargin = 60 * time.Second
+)
+
+type oauthToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+
+	expiry time.Time
+}
+
+func (t *oauthToken) valid() bool {
+	return t != nil && t.AccessToken != "" && time.Now().Add(oauthExpiryMargin).Before(t.expiry)
+}
+
+// oauthTokenSource exchanges credentials for an OAuth2 access token at the Apigee token endpoint and keeps it fresh
+// for the rest of the run.  It is safe for concurrent use.
+type oauthTokenSource struct {
+	tokenURI string
+	user     string
+	password string
+	mfaToken string
+	passcode string
+
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	token *oauthToken
+}
+
+func newOAuthTokenSource(c *Config, transport http.RoundTripper) *oauthTokenSource {
+
+	tokenURI := c.OAuthTokenURI
+	if tokenURI == "" && c.SSOZone != "" {
+		tokenURI = ssoTokenURI(c.SSOZone)
+	}
+	if tokenURI == "" {
+		tokenURI = defaultOAuthTokenURI
+	}
+
+	s := &oauthTokenSource{
+		tokenURI:   tokenURI,
+		user:       c.User,
+		password:   c.Pass,
+		mfaToken:   c.MfaToken,
+		passcode:   c.SSOPasscode,
+		httpClient: &http.Client{Transport: transport, Timeout: 60 * time.Second},
+	}
+	if c.RefreshToken != "" {
+		s.token = &oauthToken{RefreshToken: c.RefreshToken}
+	}
+
+	return s
+}
+
+// ssoTokenURI returns the token endpoint of an SSO zone.  The zone is either its name or the full zone URL.
+func ssoTokenURI(zone string) string {
+
+	zoneURL := zone
+	if !strings.Contains(zone, "://") {
+		zoneURL = fmt.Sprintf("https://%s.login.apigee.com", zone)
+	}
+
+	return strings.TrimSuffix(zoneURL, "/") + "/oauth/token"
+}
+
+// Token returns a valid access token, fetching a new one when the cached token is missing or about to expire.  ctx
+// is the context of the request the token is for, so that the fetch counts against its deadline.
+func (s *oauthTokenSource) Token(ctx context.Context) (string, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.valid() {
+		return s.token.AccessToken, nil
+	}
+
+	if s.token != nil && s.token.RefreshToken != "" {
+		token, err := s.fetch(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {s.token.RefreshToken},
+		}, "")
+		if err == nil {
+			s.token = token
+			return token.AccessToken, nil
+		}
+		if s.password == "" && s.passcode == "" {
+			return "", err
+		}
+		log.Printf("[DEBUG] oauthTokenSource refresh failed, requesting a new token: %s", err.Error())
+	}
+
+	// A passcode is single use, once exchanged the run carries on with the refresh token.
+	if s.passcode != "" {
+		passcode := s.passcode
+		s.passcode = ""
+
+		token, err := s.fetch(ctx, url.Values{
+			"grant_type":    {"password"},
+			"response_type": {"token"},
+			"passcode":      {passcode},
+		}, "")
+		if err != nil {
+			return "", err
+		}
+		s.token = token
+
+		return token.AccessToken, nil
+	}
+
+	if s.password == "" {
+		if s.token != nil && s.token.RefreshToken != "" {
+			return "", fmt.Errorf("[ERROR] oauthTokenSource the refresh token was rejected, a new sso_passcode or a password is required")
+		}
+		return "", fmt.Errorf("[ERROR] oauthTokenSource a password, sso_passcode or refresh_token is required to request an access token")
+	}
+
+	// An MFA token is single use so it is only good for the first exchange of the run.
+	mfaToken := s.mfaToken
+	s.mfaToken = ""
+
+	token, err := s.fetch(ctx, url.Values{
+		"grant_type": {"password"},
+		"username":   {s.user},
+		"password":   {s.password},
+	}, mfaToken)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+
+	return token.AccessToken, nil
+}
+
+// Invalidate drops the cached access token if it is still the given one, so that the next call to Token fetches a
+// new one.  The refresh token is kept.
+func (s *oauthTokenSource) Invalidate(accessToken string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.AccessToken == accessToken {
+		s.token.expiry = time.Time{}
+	}
+}
+
+func (s *oauthTokenSource) fetch(ctx context.Context, form url.Values, mfaToken string) (*oauthToken, error) {
+
+	tokenURL, err := url.Parse(s.tokenURI)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] oauthTokenSource invalid oauth_token_uri: %s", err.Error())
+	}
+	if mfaToken != "" {
+		q := tokenURL.Query()
+		q.Set("mfa_token", mfaToken)
+		tokenURL.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+	req.Header.Set("Accept", "application/json;charset=utf-8")
+	req.SetBasicAuth(oauthClientID, oauthClientSecret)
+
+	log.Printf("[DEBUG] oauthTokenSource requesting %s token from %s", form.Get("grant_type"), s.tokenURI)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] oauthTokenSource error requesting token: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] oauthTokenSource error reading token response: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[ERROR] oauthTokenSource token request failed: %d %s", resp.StatusCode, oauthErrorDescription(body))
+	}
+
+	token := &oauthToken{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("[ERROR] oauthTokenSource error decoding token response: %s", err.Error())
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("[ERROR] oauthTokenSource token response did not contain an access_token")
+	}
+	token.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return token, nil
+}
+
+// oauthErrorDescription pulls the error out of a token endpoint response without echoing anything else it contains.
+func oauthErrorDescription(body []byte) string {
+
+	oauthErr := struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}{}
+	if err := json.Unmarshal(body, &oauthErr); err != nil || oauthErr.Error == "" {
+		return ""
+	}
+	if oauthErr.Description == "" {
+		return oauthErr.Error
+	}
+
+	return oauthErr.Error + ": " + oauthErr.Description
+}
+
+// tokenSource hands out access tokens for the management API, fetching them within the context given.
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Invalidate(accessToken string)
+}
+
+// oauthTransport authorizes every request with a token from the source, and fetches a new token and tries once
+// more when the management API answers 401.
+type oauthTransport struct {
+	source tokenSource
+	base   http.RoundTripper
+}
+
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(authorizedRequest(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// The body has been consumed by the first attempt so only requests that can replay it are retried.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	log.Printf("[DEBUG] oauthTransport %s %s was unauthorized, refreshing the access token", req.Method, req.URL.Path)
+	t.source.Invalidate(token)
+	token, err = t.source.Token(req.Context())
+	if err != nil {
+		return resp, nil
+	}
+
+	retry := authorizedRequest(req, token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+
+	return t.base.RoundTrip(retry)
+}
+
+// authorizedRequest returns a copy of the request carrying the bearer token.  RoundTrippers must not modify the
+// request they are given.
+func authorizedRequest(req *http.Request, token string) *http.Request {
+
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+
+	return authorized
+}
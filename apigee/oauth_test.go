@@ -0,0 +1,234 @@
+package apigee
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeOAuthServer serves both the token endpoint and a single management API call so the whole chain is exercised.
+type fakeOAuthServer struct {
+	mu          sync.Mutex
+	issued      int
+	grants      []string
+	mfaTokens   []string
+	passcodes   []string
+	expiresIn   int
+	validTokens map[string]bool
+}
+
+func (f *fakeOAuthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/oauth/token" {
+		if user, pass, _ := r.BasicAuth(); user != oauthClientID || pass != oauthClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		grant := r.PostForm.Get("grant_type")
+		f.grants = append(f.grants, grant)
+		f.mfaTokens = append(f.mfaTokens, r.URL.Query().Get("mfa_token"))
+		if passcode := r.PostForm.Get("passcode"); passcode != "" {
+			f.passcodes = append(f.passcodes, passcode)
+			if passcode != "valid-passcode" || len(f.passcodes) > 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error":"unauthorized","error_description":"Invalid passcode"}`)
+				return
+			}
+		} else if grant == "password" && r.PostForm.Get("password") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"unauthorized","error_description":"Bad credentials"}`)
+			return
+		}
+
+		f.issued++
+		token := fmt.Sprintf("token-%d", f.issued)
+		f.validTokens = map[string]bool{token: true}
+		fmt.Fprintf(w, `{"access_token":%q,"refresh_token":"refresh-%d","expires_in":%d}`, token, f.issued, f.expiresIn)
+		return
+	}
+
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !f.validTokens[auth[7:]] {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	fmt.Fprint(w, `{"name":"helloworld","revision":["1"]}`)
+}
+
+func newFakeOAuthClientConfig(server *httptest.Server, password string) *Config {
+	return &Config{
+		BaseURI:       server.URL,
+		User:          "someone@example.com",
+		Pass:          password,
+		Org:           "test-org",
+		OAuthTokenURI: server.URL + "/oauth/token",
+	}
+}
+
+func TestOAuthPasswordGrant(t *testing.T) {
+
+	fake := &fakeOAuthServer{expiresIn: 1799}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := newFakeOAuthClientConfig(server, "secret").Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		proxy, _, err := client.Proxies.Get("helloworld")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if proxy.Name != "helloworld" {
+			t.Fatalf("expected helloworld, got %s", proxy.Name)
+		}
+	}
+
+	if fake.issued != 1 {
+		t.Fatalf("expected the token to be cached, %d tokens were issued", fake.issued)
+	}
+}
+
+func TestOAuthRefreshBeforeExpiry(t *testing.T) {
+
+	// Tokens which expire within the margin are refreshed before every use.
+	fake := &fakeOAuthServer{expiresIn: 30}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := newFakeOAuthClientConfig(server, "secret").Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Proxies.Get("helloworld"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	expected := []string{"password", "refresh_token"}
+	if fmt.Sprint(fake.grants) != fmt.Sprint(expected) {
+		t.Fatalf("expected grants %v, got %v", expected, fake.grants)
+	}
+}
+
+func TestOAuthRefreshOnUnauthorized(t *testing.T) {
+
+	fake := &fakeOAuthServer{expiresIn: 1799}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := newFakeOAuthClientConfig(server, "secret").Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, _, err := client.Proxies.Get("helloworld"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Revoke the token server side.
+	fake.mu.Lock()
+	fake.validTokens = map[string]bool{}
+	fake.mu.Unlock()
+
+	if _, _, err := client.Proxies.Get("helloworld"); err != nil {
+		t.Fatalf("expected the token to be refreshed after a 401, got: %s", err)
+	}
+	if fake.issued != 2 {
+		t.Fatalf("expected 2 tokens to be issued, got %d", fake.issued)
+	}
+}
+
+func TestOAuthMfaTokenUsedOnce(t *testing.T) {
+
+	fake := &fakeOAuthServer{expiresIn: 0}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source := newOAuthTokenSource(&Config{OAuthTokenURI: server.URL + "/oauth/token", User: "someone@example.com", Pass: "secret", MfaToken: "123456"}, baseTransport())
+	for i := 0; i < 2; i++ {
+		source.token = nil
+		if _, err := source.Token(context.Background()); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	expected := []string{"123456", ""}
+	if fmt.Sprint(fake.mfaTokens) != fmt.Sprint(expected) {
+		t.Fatalf("expected mfa tokens %v, got %v", expected, fake.mfaTokens)
+	}
+}
+
+func TestOAuthBadCredentials(t *testing.T) {
+
+	fake := &fakeOAuthServer{expiresIn: 1799}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source := newOAuthTokenSource(&Config{OAuthTokenURI: server.URL + "/oauth/token", User: "someone@example.com", Pass: "wrong"}, baseTransport())
+	_, err := source.Token(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if expected := "401 unauthorized: Bad credentials"; !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected error to contain %q, got: %s", expected, err)
+	}
+}
+
+func TestOAuthSSOPasscode(t *testing.T) {
+
+	// The token expires within the margin so the second call has to use the refresh token, the passcode is single use.
+	fake := &fakeOAuthServer{expiresIn: 30}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	config := &Config{
+		BaseURI:     server.URL,
+		Org:         "test-org",
+		SSOZone:     server.URL,
+		SSOPasscode: "valid-passcode",
+	}
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Proxies.Get("helloworld"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	if len(fake.passcodes) != 1 {
+		t.Fatalf("expected the passcode to be exchanged once, got %v", fake.passcodes)
+	}
+	expected := []string{"password", "refresh_token"}
+	if fmt.Sprint(fake.grants) != fmt.Sprint(expected) {
+		t.Fatalf("expected grants %v, got %v", expected, fake.grants)
+	}
+}
+
+func TestSSOTokenURI(t *testing.T) {
+
+	cases := map[string]string{
+		"acme":                               "https://acme.login.apigee.com/oauth/token",
+		"https://acme.login.apigee.com":      "https://acme.login.apigee.com/oauth/token",
+		"https://sso.example.com/zone/acme/": "https://sso.example.com/zone/acme/oauth/token",
+	}
+	for zone, expected := range cases {
+		if actual := ssoTokenURI(zone); actual != expected {
+			t.Errorf("ssoTokenURI(%q) = %q, expected %q", zone, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package apigee
+
+import (
+	"path"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+const (
+	operationConfigTypeProxy         = "proxy"
+	operationConfigTypeRemoteService = "remoteservice"
+)
+
+// product is an API product with its operation group.  go-apigee-edge's Product predates operation groups so products
+// are read and written here instead.
+type product struct {
+	apigee.Product
+	OperationGroup *productOperationGroup `json:"operationGroup,omitempty"`
+}
+
+// productOperationGroup binds each API source (a proxy or a remote service) to resource paths and methods, each with
+// its own quota.
+type productOperationGroup struct {
+	OperationConfigs    []productOperationConfig `json:"operationConfigs"`
+	OperationConfigType string                   `json:"operationConfigType,omitempty"`
+}
+
+type productOperationConfig struct {
+	ApiSource  string                 `json:"apiSource"`
+	Operations []productOperation     `json:"operations,omitempty"`
+	Quota      *productOperationQuota `json:"quota,omitempty"`
+	Attributes []apigee.Attribute     `json:"attributes,omitempty"`
+}
+
+type productOperation struct {
+	Resource string   `json:"resource"`
+	Methods  []string `json:"methods,omitempty"`
+}
+
+// productOperationQuota is sent with strings for numbers, which is what Apigee returns.
+type productOperationQuota struct {
+	Limit    string `json:"limit,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	TimeUnit string `json:"timeUnit,omitempty"`
+}
+
+func getProduct(client *apigee.EdgeClient, name string) (*product, error) {
+
+	req, err := client.NewRequest("GET", path.Join("apiproducts", name), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	p := product{}
+	if _, err := client.Do(req, &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// createOrUpdateProduct creates the product with a POST or replaces it with a PUT.
+func createOrUpdateProduct(client *apigee.EdgeClient, method string, p product) error {
+
+	productPath := "apiproducts"
+	if method == "PUT" {
+		productPath = path.Join(productPath, p.Name)
+	}
+
+	req, err := client.NewRequest(method, productPath, p, "")
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(req, nil)
+
+	return err
+}
+
+// expandProductOperationGroup returns the operation group of the product's operation blocks, nil when there are none.
+// Every resource path of a block is sent as an operation with the block's methods.
+func expandProductOperationGroup(d *schema.ResourceData) *productOperationGroup {
+
+	operations := d.Get("operation").([]interface{})
+	if len(operations) == 0 {
+		return nil
+	}
+
+	group := productOperationGroup{OperationConfigType: d.Get("operation_config_type").(string)}
+	for _, operation := range operations {
+		operation := operation.(map[string]interface{})
+
+		config := productOperationConfig{
+			ApiSource:  operation["api_source"].(string),
+			Attributes: attributesFromMap(operation["attributes"].(map[string]interface{})),
+		}
+
+		methods := []string{}
+		for _, method := range operation["methods"].([]interface{}) {
+			methods = append(methods, method.(string))
+		}
+		for _, resource := range operation["resources"].([]interface{}) {
+			config.Operations = append(config.Operations, productOperation{Resource: resource.(string), Methods: methods})
+		}
+
+		if quota := operation["quota"].(int); quota > 0 {
+			config.Quota = &productOperationQuota{
+				Limit:    quotaString(quota),
+				Interval: quotaString(operation["quota_interval"].(int)),
+				TimeUnit: operation["quota_time_unit"].(string),
+			}
+		}
+
+		group.OperationConfigs = append(group.OperationConfigs, config)
+	}
+
+	return &group
+}
+
+// flattenProductOperationGroup returns the operation blocks of an operation group.  Operations of one config that
+// use different methods cannot be written as one block, the methods of the first are used.
+func flattenProductOperationGroup(group *productOperationGroup) []interface{} {
+
+	if group == nil {
+		return []interface{}{}
+	}
+
+	result := make([]interface{}, 0, len(group.OperationConfigs))
+	for _, config := range group.OperationConfigs {
+		resources := []string{}
+		methods := []string{}
+		for i, operation := range config.Operations {
+			resources = append(resources, operation.Resource)
+			if i == 0 {
+				methods = operation.Methods
+			}
+		}
+
+		attributes := make(map[string]interface{}, len(config.Attributes))
+		for _, attribute := range config.Attributes {
+			attributes[attribute.Name] = attribute.Value
+		}
+
+		operation := map[string]interface{}{
+			"api_source": config.ApiSource,
+			"resources":  flattenStringList(resources),
+			"methods":    flattenStringList(methods),
+			"attributes": attributes,
+		}
+		if config.Quota != nil {
+			operation["quota"] = quotaInt(config.Quota.Limit)
+			operation["quota_interval"] = quotaInt(config.Quota.Interval)
+			operation["quota_time_unit"] = config.Quota.TimeUnit
+		}
+
+		result = append(result, operation)
+	}
+
+	return result
+}
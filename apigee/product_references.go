@@ -0,0 +1,139 @@
+package apigee
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+// validateProductReferences checks that the proxies and environments a product names exist and, with
+// validate_deployments, that every proxy is deployed to each of the product's environments (to any environment when
+// it lists none).  Apigee accepts products naming proxies that do not exist, which then grant nothing.
+func validateProductReferences(d *schema.ResourceDiff, meta interface{}) error {
+
+	for _, key := range []string{"org", "proxies", "environments", "operation"} {
+		if !d.NewValueKnown(key) {
+			// The references are checked on the next plan, once they are known.
+			return nil
+		}
+	}
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] validateProductReferences %s", err.Error())
+	}
+
+	proxies := productProxyNames(d)
+	environments := []string{}
+	for _, env := range d.Get("environments").(*schema.Set).List() {
+		environments = append(environments, env.(string))
+	}
+
+	problems := []string{}
+
+	if len(environments) > 0 {
+		existing, err := listEnvironments(client)
+		if err != nil {
+			log.Printf("[ERROR] validateProductReferences error listing environments: %s", err.Error())
+			return fmt.Errorf("[ERROR] validateProductReferences error listing environments: %s", err.Error())
+		}
+		for _, env := range environments {
+			if !containsString(existing, env) {
+				problems = append(problems, fmt.Sprintf("environment %s does not exist", env))
+			}
+		}
+	}
+
+	for _, proxy := range proxies {
+		if _, _, err := client.Proxies.Get(proxy); err != nil {
+			if isNotFound(err) {
+				problems = append(problems, fmt.Sprintf("proxy %s does not exist", proxy))
+				continue
+			}
+			log.Printf("[ERROR] validateProductReferences error reading proxy %s: %s", proxy, err.Error())
+			return fmt.Errorf("[ERROR] validateProductReferences error reading proxy %s: %s", proxy, err.Error())
+		}
+
+		if !d.Get("validate_deployments").(bool) {
+			continue
+		}
+		deployments, _, err := client.Proxies.GetDeployments(proxy)
+		if err != nil {
+			log.Printf("[ERROR] validateProductReferences error reading deployments of proxy %s: %s", proxy, err.Error())
+			return fmt.Errorf("[ERROR] validateProductReferences error reading deployments of proxy %s: %s", proxy, err.Error())
+		}
+		if len(environments) == 0 && !isDeployed(deployments.Environments) {
+			problems = append(problems, fmt.Sprintf("proxy %s is not deployed", proxy))
+		}
+		for _, env := range environments {
+			if _, found := lastDeployedRevision(deployments.Environments, env); !found {
+				problems = append(problems, fmt.Sprintf("proxy %s is not deployed to %s", proxy, env))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("[ERROR] validateProductReferences %s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// productProxyNames returns the proxies of the product and of its operations, sorted and without duplicates.
+func productProxyNames(d *schema.ResourceDiff) []string {
+
+	names := map[string]bool{}
+	for _, proxy := range d.Get("proxies").(*schema.Set).List() {
+		names[proxy.(string)] = true
+	}
+	if d.Get("operation_config_type").(string) == operationConfigTypeProxy {
+		for _, operation := range d.Get("operation").([]interface{}) {
+			names[operation.(map[string]interface{})["api_source"].(string)] = true
+		}
+	}
+
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func listEnvironments(client *apigee.EdgeClient) ([]string, error) {
+
+	req, err := client.NewRequest("GET", "environments", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	environments := []string{}
+	if _, err := client.Do(req, &environments); err != nil {
+		return nil, err
+	}
+
+	return environments, nil
+}
+
+func isDeployed(environments []apigee.EnvironmentDeployment) bool {
+	for _, environment := range environments {
+		if len(environment.Revision) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
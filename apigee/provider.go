@@ -2,14 +2,25 @@
 package apigee
 
 import (
+	"fmt"
+	"math"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/zambien/go-apigee-edge"
 )
 
 func Provider() terraform.ResourceProvider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
+			"flavor": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APIGEE_FLAVOR", flavorEdge),
+				ValidateFunc: validation.StringInSlice([]string{flavorEdge, flavorX}, false),
+				Description:  "Apigee management API to talk to, edge or x (Apigee X and hybrid)",
+			},
 			"base_uri": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -40,11 +51,140 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("APIGEE_ORG", nil),
 				Description: "Apigee Organization",
 			},
+			"oauth_token_uri": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_OAUTH_TOKEN_URI", nil),
+				Description: "Apigee OAuth2 token endpoint.  When set user and password are exchanged for an access token.",
+			},
+			"mfa_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_MFA_TOKEN", nil),
+				Description: "Apigee one time MFA token for the OAuth2 token exchange",
+			},
+			"refresh_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_REFRESH_TOKEN", nil),
+				Description: "Apigee OAuth2 refresh token",
+			},
+			"sso_zone": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_SSO_ZONE", nil),
+				Description: "Apigee SSO zone name or URL the sso_passcode is exchanged at",
+			},
+			"sso_passcode": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_SSO_PASSCODE", nil),
+				Description: "Apigee SSO one time passcode",
+			},
+			"credentials": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"APIGEE_CREDENTIALS", "GOOGLE_APPLICATION_CREDENTIALS"}, nil),
+				Description: "Apigee X service account key file or its JSON contents",
+			},
+			"max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APIGEE_MAX_RETRIES", defaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of times a request failing with 429, 5xx or a dropped connection is retried",
+			},
+			"retry_min_wait": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(defaultRetryMinWait / time.Second),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Seconds to wait before the first retry, doubled on every further retry",
+			},
+			"retry_max_wait": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(defaultRetryMaxWait / time.Second),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum seconds to wait between retries",
+			},
+			"requests_per_second": &schema.Schema{
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APIGEE_REQUESTS_PER_SECOND", 0.0),
+				ValidateFunc: validation.FloatBetween(0, math.MaxFloat64),
+				Description:  "Maximum rate of management API requests, 0 for unlimited",
+			},
+			"max_concurrent_requests": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APIGEE_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of management API requests in flight at once, 0 for unlimited",
+			},
+			"ca_cert_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_CA_CERT_FILE", ""),
+				Description: "PEM file of a CA to trust for the management API, in addition to the system's",
+			},
+			"client_cert_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_CLIENT_CERT_FILE", ""),
+				Description: "PEM file of the client certificate for mutual TLS with the management API",
+			},
+			"client_key_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_CLIENT_KEY_FILE", ""),
+				Description: "PEM file of the private key of client_cert_file",
+			},
+			"insecure_skip_verify": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_INSECURE_SKIP_VERIFY", false),
+				Description: "Skip verifying the management API's certificate.  For lab environments only",
+			},
+			"http_proxy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_HTTP_PROXY", ""),
+				Description: "Proxy URL for management API requests, HTTP_PROXY and HTTPS_PROXY are used when unset",
+			},
+			"default_attributes": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Attributes added to every product, developer, developer app, company and company app",
+			},
+			"debug_http": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_DEBUG_HTTP", false),
+				Description: "Log management API requests and responses, secrets redacted, when TF_LOG is DEBUG",
+			},
+			"no_proxy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APIGEE_NO_PROXY", ""),
+				Description: "Comma separated hosts to reach without the proxy, overrides NO_PROXY",
+			},
 		},
 
-		ResourcesMap: map[string]*schema.Resource{
+		DataSourcesMap: map[string]*schema.Resource{
+			"apigee_api_proxy_bundle":   dataSourceApiProxyBundle(),
+			"apigee_shared_flow_bundle": dataSourceSharedFlowBundle(),
+		},
+
+		ResourcesMap: withDeadlines(map[string]*schema.Resource{
 			"apigee_api_proxy":              resourceApiProxy(),
 			"apigee_api_proxy_deployment":   resourceApiProxyDeployment(),
+			"apigee_api_proxy_revision":     resourceApiProxyRevision(),
 			"apigee_company":                resourceCompany(),
 			"apigee_company_app":            resourceCompanyApp(),
 			"apigee_developer":              resourceDeveloper(),
@@ -53,27 +193,50 @@ func Provider() terraform.ResourceProvider {
 			"apigee_target_server":          resourceTargetServer(),
 			"apigee_shared_flow":            resourceSharedFlow(),
 			"apigee_shared_flow_deployment": resourceSharedFlowDeployment(),
-		},
+		}),
 
 		ConfigureFunc: configureProvider,
 	}
 }
 
 func configureProvider(d *schema.ResourceData) (interface{}, error) {
-	auth := apigee.EdgeAuth{
-		Username:    d.Get("user").(string),
-		Password:    d.Get("password").(string),
-		AccessToken: d.Get("access_token").(string),
+	config := Config{
+		Flavor:        d.Get("flavor").(string),
+		BaseURI:       d.Get("base_uri").(string),
+		User:          d.Get("user").(string),
+		Pass:          d.Get("password").(string),
+		AccessToken:   d.Get("access_token").(string),
+		Org:           d.Get("org").(string),
+		OAuthTokenURI: d.Get("oauth_token_uri").(string),
+		MfaToken:      d.Get("mfa_token").(string),
+		RefreshToken:  d.Get("refresh_token").(string),
+		SSOZone:       d.Get("sso_zone").(string),
+		SSOPasscode:   d.Get("sso_passcode").(string),
+		Credentials:   d.Get("credentials").(string),
+		MaxRetries:    d.Get("max_retries").(int),
+		RetryMinWait:  time.Duration(d.Get("retry_min_wait").(int)) * time.Second,
+		RetryMaxWait:  time.Duration(d.Get("retry_max_wait").(int)) * time.Second,
+
+		RequestsPerSecond:     d.Get("requests_per_second").(float64),
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+
+		CACertFile:         d.Get("ca_cert_file").(string),
+		ClientCertFile:     d.Get("client_cert_file").(string),
+		ClientKeyFile:      d.Get("client_key_file").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+		HTTPProxy:          d.Get("http_proxy").(string),
+		NoProxy:            d.Get("no_proxy").(string),
+		DebugHTTP:          d.Get("debug_http").(bool),
 	}
-	opts := &apigee.EdgeClientOptions{
-		MgmtUrl: d.Get("base_uri").(string),
-		Org:     d.Get("org").(string),
-		Auth:    &auth, Debug: false,
+
+	if config.RetryMaxWait < config.RetryMinWait {
+		return nil, fmt.Errorf("[ERROR] retry_max_wait (%s) must not be less than retry_min_wait (%s)", config.RetryMaxWait, config.RetryMinWait)
 	}
 
-	client, err := apigee.NewEdgeClient(opts)
-	if err != nil {
-		return client, err
+	config.DefaultAttributes = map[string]string{}
+	for k, v := range d.Get("default_attributes").(map[string]interface{}) {
+		config.DefaultAttributes[k] = v.(string)
 	}
-	return client, nil
+
+	return config.Clients()
 }
@@ -3,9 +3,11 @@
 package apigee
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
@@ -41,3 +43,9 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("APIGEE_ORG must be set for acceptance tests")
 	}
 }
+
+// testCheckResourceSetAttr checks that the set of strings key of the resource name contains value.
+func testCheckResourceSetAttr(name string, key string, value string) resource.TestCheckFunc {
+	hash := schema.HashSchema(&schema.Schema{Type: schema.TypeString})(value)
+	return resource.TestCheckResourceAttr(name, fmt.Sprintf("%s.%d", key, hash), value)
+}
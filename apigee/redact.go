@@ -0,0 +1,109 @@
+package apigee
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveWords mark a field, form value or query parameter as secret when its name contains one of them, compared
+// ignoring case and separators: consumerSecret, client_secret, password, keyPassword, access_token, mfa_token,
+// passcode, private_key and so on.
+var sensitiveWords = []string{"secret", "password", "passphrase", "token", "passcode", "privatekey", "assertion"}
+
+func isSensitive(name string) bool {
+
+	name = strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(name))
+	for _, word := range sensitiveWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactJSON returns data with every secret field replaced.  KVM entries keep their names but lose their values.
+// Anything that is not JSON is returned as it is.
+func redactJSON(data []byte, kvm bool) []byte {
+
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return data
+	}
+
+	redactedData, err := json.Marshal(redactValue(v, kvm))
+	if err != nil {
+		return data
+	}
+
+	return redactedData
+}
+
+func redactValue(v interface{}, kvm bool) interface{} {
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// Attributes and KVM entries are {"name": "...", "value": "..."} pairs, the name tells whether the value is secret.
+		name, _ := v["name"].(string)
+		secretValue := kvm || isSensitive(name)
+		for key, value := range v {
+			if isSensitive(key) || (key == "value" && secretValue) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(value, kvm)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value, kvm)
+		}
+	}
+
+	return v
+}
+
+// redactForm returns an url encoded form or query with every secret value replaced.
+func redactForm(form string) string {
+
+	values, err := url.ParseQuery(form)
+	if err != nil {
+		return form
+	}
+	for key := range values {
+		if isSensitive(key) {
+			values[key] = []string{redacted}
+		}
+	}
+
+	return values.Encode()
+}
+
+// redactURL returns u with every secret query parameter replaced.
+func redactURL(u *url.URL) string {
+
+	if u.RawQuery == "" {
+		return u.String()
+	}
+
+	redactedURL := *u
+	redactedURL.RawQuery = redactForm(u.RawQuery)
+	return redactedURL.String()
+}
+
+// redactedObject formats an object for the log with its secrets replaced, in place of %+v.
+func redactedObject(v interface{}) string {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<%T>", v)
+	}
+
+	return string(redactJSON(data, false))
+}
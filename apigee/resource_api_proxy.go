@@ -1,15 +1,12 @@
 package apigee
 
 import (
-	"strings"
-
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/gofrs/uuid"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/zambien/go-apigee-edge"
 )
 
 func resourceApiProxy() *schema.Resource {
@@ -21,13 +18,41 @@ func resourceApiProxy() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: resourceApiProxyImport,
 		},
+		CustomizeDiff: resourceApiProxyCustomizeDiff,
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceApiProxyV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceApiProxyStateUpgradeV0,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
 				ForceNew: true,
 			},
+			//deletion_protection makes destroying the proxy fail until it is set to false by an apply.
+			"deletion_protection": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"bundle": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -36,7 +61,7 @@ func resourceApiProxy() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
-			//revision_sha is used as a workaround for: https://github.com/hashicorp/terraform/issues/15857
+			//revision_sha is a hash of the latest revision on the server so that changes made outside of terraform show up in the plan.
 			"revision_sha": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -52,9 +77,10 @@ func resourceApiProxy() *schema.Resource {
 func resourceApiProxyCreate(d *schema.ResourceData, meta interface{}) error {
 	log.Print("[DEBUG] resourceApiProxyCreate START")
 
-	client := meta.(*apigee.EdgeClient)
-
-	u1, _ := uuid.NewV4()
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyCreate %s", err.Error())
+	}
 
 	proxyRev, _, err := client.Proxies.Import(d.Get("name").(string), d.Get("bundle").(string))
 
@@ -63,10 +89,10 @@ func resourceApiProxyCreate(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("[ERROR] resourceApiProxyCreate error importing api_proxy: %s", err.Error())
 	}
 
-	d.SetId(u1.String())
+	//The ID is the import ID so that created and imported proxies are alike.
+	d.SetId(d.Get("name").(string))
 	d.Set("name", d.Get("name").(string))
 	d.Set("revision", proxyRev.Revision.String())
-	d.Set("revision_sha", d.Get("bundle_sha").(string))
 
 	return resourceApiProxyRead(d, meta)
 }
@@ -74,26 +100,34 @@ func resourceApiProxyCreate(d *schema.ResourceData, meta interface{}) error {
 func resourceApiProxyImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	log.Print("[DEBUG] resourceApiProxyImport START")
 
-	client := meta.(*apigee.EdgeClient)
-	proxy, _, err := client.Proxies.Get(d.Id())
-	if err != nil {
-		return []*schema.ResourceData{}, fmt.Errorf("[DEBUG] resourceApiProxyImport. Error getting deployment api: %v", err)
-	}
-	latestRev := proxy.Revisions[len(proxy.Revisions)-1]
-	d.Set("revision", latestRev)
+	importOrg(d)
+
 	d.Set("name", d.Id())
+	setImportDefaults(d, resourceApiProxy())
+
+	if err := resourceApiProxyRead(d, meta); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceApiProxyImport error reading proxy: %s", err.Error())
+	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceApiProxyImport proxy %s does not exist", d.Get("name").(string))
+	}
+
 	return []*schema.ResourceData{d}, nil
 }
 
 func resourceApiProxyRead(d *schema.ResourceData, meta interface{}) error {
 	log.Print("[DEBUG] resourceApiProxyRead START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	u, _, err := client.Proxies.Get(d.Get("name").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceApiProxyRead error reading proxies: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceApiProxyRead 404 encountered.  Removing state for proxy: %#v", d.Get("name").(string))
 			d.SetId("")
 			return nil
@@ -104,10 +138,21 @@ func resourceApiProxyRead(d *schema.ResourceData, meta interface{}) error {
 
 	latest_rev := u.Revisions[len(u.Revisions)-1]
 
+	bundle, err := exportRevision(client, proxiesPath, u.Name, latest_rev)
+	if err != nil {
+		log.Printf("[ERROR] resourceApiProxyRead error exporting revision %s: %s", latest_rev, err.Error())
+		return fmt.Errorf("[ERROR] resourceApiProxyRead error exporting revision %s: %s", latest_rev, err.Error())
+	}
+
+	revisionSha, err := bundleSha(bundle)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyRead error hashing revision %s: %s", latest_rev, err.Error())
+	}
+
 	log.Printf("[DEBUG] resourceApiProxyRead.  revision_sha before: %#v", d.Get("revision_sha").(string))
-	d.Set("revision_sha", d.Get("bundle_sha").(string))
+	d.Set("revision_sha", revisionSha)
 	log.Printf("[DEBUG] resourceApiProxyRead.  revision_sha after: %#v", d.Get("revision_sha").(string))
-	d.Set("revision", latest_rev)
+	d.Set("revision", latest_rev.String())
 	d.Set("name", u.Name)
 
 	return nil
@@ -117,7 +162,14 @@ func resourceApiProxyUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceApiProxyUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyUpdate %s", err.Error())
+	}
+
+	if onlyDeletionProtectionChanged(d, resourceApiProxy()) {
+		return resourceApiProxyRead(d, meta)
+	}
 
 	if d.HasChange("name") {
 		log.Printf("[INFO] resourceApiProxyUpdate name changed to: %#v\n", d.Get("name"))
@@ -134,38 +186,64 @@ func resourceApiProxyUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	d.Set("revision", proxyRev.Revision.String())
-	d.Set("revision_sha", d.Get("bundle_sha").(string))
 
 	return resourceApiProxyRead(d, meta)
 }
 
+// resourceApiProxyCustomizeDiff compares the local bundle with the latest revision read from the server.  If the revision
+// was edited outside of terraform (e.g. in the Edge UI) the hashes differ and the bundle is imported again on apply.
+func resourceApiProxyCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+
+	if d.Id() == "" || !d.NewValueKnown("bundle") {
+		return nil
+	}
+
+	localSha, err := bundleFileSha(d.Get("bundle").(string))
+	if err != nil {
+		log.Printf("[ERROR] resourceApiProxyCustomizeDiff error hashing bundle %s: %s", d.Get("bundle").(string), err.Error())
+		return fmt.Errorf("[ERROR] resourceApiProxyCustomizeDiff error hashing bundle %s: %s", d.Get("bundle").(string), err.Error())
+	}
+
+	if localSha != d.Get("revision_sha").(string) {
+		log.Printf("[INFO] resourceApiProxyCustomizeDiff latest revision differs from bundle %#v", d.Get("bundle").(string))
+		return d.SetNew("revision_sha", localSha)
+	}
+
+	return nil
+}
+
 func resourceApiProxyDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceApiProxyDelete START")
 
-	client := meta.(*apigee.EdgeClient)
+	if err := checkDeletionProtection(d, fmt.Sprintf("proxy %s", d.Get("name").(string))); err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyDelete %s", err.Error())
+	}
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyDelete %s", err.Error())
+	}
 
 	//We have to handle retries in a special way here since this is a DELETE.  Note this used to work fine without retries.
-	deleted := false
-	tries := 0
-	for !deleted && tries < 3 {
+	err = resource.Retry(d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
 		_, _, err := client.Proxies.Delete(d.Get("name").(string))
 		if err != nil {
+			if isNotFound(err) {
+				return nil
+			}
 			//This is a race condition with Apigee APIs.  Wait and try again.
-			if strings.Contains(err.Error(), "Undeploy the ApiProxy and try again") {
-				log.Printf("[ERROR] resourceApiProxyDelete proxy still exists.  We will wait and try again.")
-				time.Sleep(5 * time.Second)
-			} else {
-				log.Printf("[ERROR] resourceApiProxyDelete error deleting api_proxy: %s", err.Error())
-				return fmt.Errorf("[ERROR] resourceApiProxyDelete error deleting api_proxy: %s", err.Error())
+			if isConflict(err) || isRateLimited(err) {
+				log.Printf("[ERROR] resourceApiProxyDelete api_proxy still exists.  We will wait and try again.")
+				return resource.RetryableError(err)
 			}
-		} else {
-			deleted = true
+			return resource.NonRetryableError(err)
 		}
-		tries += 1
-	}
-	if !deleted {
-		return fmt.Errorf("[ERROR] unable to delete ApiProxy")
+		return nil
+	})
+	if err != nil {
+		log.Printf("[ERROR] resourceApiProxyDelete error deleting api_proxy: %s", err.Error())
+		return fmt.Errorf("[ERROR] unable to delete ApiProxy: %s", err.Error())
 	}
 
 	return nil
@@ -5,9 +5,10 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/zambien/go-apigee-edge"
 )
 
@@ -20,6 +21,27 @@ func resourceApiProxyDeployment() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: resourceApiProxyDeploymentImport,
 		},
+		CustomizeDiff: deploymentCustomizeDiff,
+
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceApiProxyDeploymentV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceApiProxyDeploymentStateUpgradeV0,
+			},
+			{
+				Version: 1,
+				Type:    resourceApiProxyDeploymentV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceApiProxyDeploymentStateUpgradeV1,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"proxy_name": {
@@ -28,9 +50,10 @@ func resourceApiProxyDeployment() *schema.Resource {
 				ForceNew: true,
 			},
 			"org": {
-				Type:       schema.TypeString,
-				Optional:   true,
-				Deprecated: "org is not required, the value from the provider is used.",
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
 			},
 			"env": {
 				Type:     schema.TypeString,
@@ -41,12 +64,40 @@ func resourceApiProxyDeployment() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			//deletion_protection makes destroying the deployment fail until it is set to false by an apply.
+			"deletion_protection": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"delay": {
 				Type:     schema.TypeInt,
 				Optional: true,
 				Default:  0,
 			},
+			//override = true picks strategy seamless.  false is the default, it cannot be told from override not being
+			//set and leaves the choice to strategy.
 			"override": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"strategy"},
+				Deprecated:    "use strategy, override = true is strategy seamless",
+			},
+			//strategy is how a revision replaces the one deployed, the same on create and update.  delay applies to
+			//seamless deployments only.
+			"strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      deploymentStrategySeamless,
+				ValidateFunc: validation.StringInSlice(deploymentStrategies, false),
+			},
+			//previous_revision is the revision that was deployed to the environment before the last deployment.
+			"previous_revision": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"wait_for_ready": {
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
@@ -57,24 +108,26 @@ func resourceApiProxyDeployment() *schema.Resource {
 
 func resourceApiProxyDeploymentImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	log.Print("[DEBUG] resourceApiProxyDeploymentImport START")
-	client := meta.(*apigee.EdgeClient)
 
-	splits := strings.Split(d.Id(), "_")
-	if len(splits) < 2 {
+	importOrg(d)
+
+	splits := strings.Split(strings.TrimSuffix(d.Id(), "_deployment"), "_")
+	if len(splits) < 2 || !strings.HasSuffix(d.Id(), "_deployment") {
 		return []*schema.ResourceData{}, fmt.Errorf("[ERR] Wrong format of resource: %s. Please follow '{name}_{env}_deployment'", d.Id())
 	}
-	nameOffset := len(splits[len(splits)-1]) + len(splits[len(splits)-2])
-	envOffset := len(splits[len(splits)-1])
-	name := d.Id()[:(len(d.Id())-nameOffset)-2]
-	IDEnv := d.Id()[len(name)+1 : (len(d.Id())-envOffset)-1]
-	deployment, _, err := client.Proxies.GetDeployments(name)
-	if err != nil {
-		log.Printf("[DEBUG] resourceApiProxyDeploymentImport. Error getting deployment api: %v", err)
-		return nil, nil
-	}
-	d.Set("org", deployment.Organization)
-	d.Set("proxy_name", deployment.Name)
+	IDEnv := splits[len(splits)-1]
+	name := strings.TrimSuffix(d.Id(), "_"+IDEnv+"_deployment")
+
+	d.Set("proxy_name", name)
 	d.Set("env", IDEnv)
+	setImportDefaults(d, resourceApiProxyDeployment())
+
+	if err := resourceApiProxyDeploymentRead(d, meta); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceApiProxyDeploymentImport error reading deployment: %s", err.Error())
+	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceApiProxyDeploymentImport proxy %s is not deployed to %s", name, IDEnv)
+	}
 
 	return []*schema.ResourceData{d}, nil
 }
@@ -83,14 +136,18 @@ func resourceApiProxyDeploymentRead(d *schema.ResourceData, meta interface{}) (e
 	log.Print("[DEBUG] resourceApiProxyDeploymentRead START")
 	log.Printf("[DEBUG] resourceApiProxyDeploymentRead proxy_name: %#v", d.Get("proxy_name").(string))
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	found := false
 	matchedRevision := "0"
 
 	if deployments, _, err := client.Proxies.GetDeployments(d.Get("proxy_name").(string)); err != nil {
 		log.Printf("[ERROR] resourceApiProxyDeploymentRead error getting deployments: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceApiProxyDeploymentRead 404 encountered.  Removing state for deployment proxy_name: %#v", d.Get("proxy_name").(string))
 			d.SetId("")
 			return nil
@@ -153,14 +210,15 @@ func resourceApiProxyDeploymentCreate(d *schema.ResourceData, meta interface{})
 
 	log.Print("[DEBUG] resourceApiProxyDeploymentCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentCreate %s", err.Error())
+	}
 
 	proxy_name := d.Get("proxy_name").(string)
 	env := d.Get("env").(string)
 	rev_int, _ := strconv.Atoi(d.Get("revision").(string))
 	rev := apigee.Revision(rev_int)
-	delay := int(d.Get("delay").(int))
-	override := bool(d.Get("override").(bool))
 
 	if d.Get("revision").(string) == "latest" {
 		// deploy latest
@@ -171,28 +229,23 @@ func resourceApiProxyDeploymentCreate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	proxyDep, _, err := client.Proxies.Deploy(proxy_name, env, rev, delay, override)
+	if err := deployRevision(client, d, proxiesPath, proxy_name, rev, d.Timeout(schema.TimeoutCreate)); err != nil {
+		log.Printf("[ERROR] resourceApiProxyDeploymentCreate %s", err.Error())
+		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentCreate %s", err.Error())
+	}
 
-	if err != nil {
-		if strings.Contains(err.Error(), " is already deployed ") {
-			log.Printf("[ERROR] resourceApiProxyDeploymentCreate error deploying.  We will read into state: %s", err.Error())
-			resourceApiProxyDeploymentUpdate(d, meta)
-		} else if strings.Contains(err.Error(), "conflicts with existing deployment path") {
-			//create, fail, update
-			log.Printf("[ERROR] resourceApiProxyDeploymentCreate error deploying: %s", err.Error())
-			log.Print("[DEBUG] resourceApiProxyDeploymentCreate something got out of sync... maybe someone messing around in apigee directly.  Terraform OVERRIDE!!!")
-			resourceApiProxyDeploymentUpdate(d, meta)
-		} else {
-			log.Printf("[ERROR] resourceApiProxyDeploymentCreate error deploying: %s", err.Error())
-			return fmt.Errorf("[ERROR] resourceApiProxyDeploymentCreate error deploying: %s", err.Error())
+	//The ID is the import ID so that created and imported deployments are alike.
+	d.SetId(fmt.Sprintf("%s_%s_deployment", proxy_name, env))
+	d.Set("revision", rev.String())
+
+	log.Printf("[DEBUG] resourceApiProxyDeploymentCreate Deployed revision %d of %s", rev, proxy_name)
+
+	if d.Get("wait_for_ready").(bool) {
+		if err := waitForDeploymentReady(client, proxiesPath, proxy_name, env, rev, d.Timeout(schema.TimeoutCreate)); err != nil {
+			return fmt.Errorf("[ERROR] resourceApiProxyDeploymentCreate %s", err.Error())
 		}
 	}
 
-	id, _ := uuid.NewV4()
-	d.SetId(id.String())
-	d.Set("revision", proxyDep.Revision.String())
-
-	log.Printf("[DEBUG] resourceApiProxyDeploymentUpdate Deployed revision %d of %s", rev, proxy_name)
 	return resourceApiProxyDeploymentRead(d, meta)
 }
 
@@ -200,20 +253,17 @@ func resourceApiProxyDeploymentUpdate(d *schema.ResourceData, meta interface{})
 
 	log.Print("[DEBUG] resourceApiProxyDeploymentUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentUpdate %s", err.Error())
+	}
+
+	if onlyDeletionProtectionChanged(d, resourceApiProxyDeployment()) {
+		return resourceApiProxyDeploymentRead(d, meta)
+	}
 
 	proxy_name := d.Get("proxy_name").(string)
 	env := d.Get("env").(string)
-	delay := int(d.Get("delay").(int))
-	override := bool(d.Get("override").(bool))
-
-	//We must set delay and override here if not set.
-	if delay == 0 {
-		delay = 15 //seconds
-	}
-	if override == false {
-		override = true
-	}
 
 	rev_int, _ := strconv.Atoi(d.Get("revision").(string))
 	rev := apigee.Revision(rev_int)
@@ -226,17 +276,19 @@ func resourceApiProxyDeploymentUpdate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	_, _, err := client.Proxies.ReDeploy(proxy_name, env, rev, delay, override)
+	if err := deployRevision(client, d, proxiesPath, proxy_name, rev, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		log.Printf("[ERROR] resourceApiProxyDeploymentUpdate %s", err.Error())
+		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentUpdate %s", err.Error())
+	}
 
-	if err != nil {
-		log.Printf("[ERROR] resourceApiProxyDeploymentUpdate error redeploying: %s", err.Error())
-		if strings.Contains(err.Error(), " is already deployed ") {
-			return resourceApiProxyDeploymentRead(d, meta)
+	log.Printf("[DEBUG] resourceApiProxyDeploymentUpdate Deployed revision %d of %s", rev, proxy_name)
+
+	if d.Get("wait_for_ready").(bool) {
+		if err := waitForDeploymentReady(client, proxiesPath, proxy_name, env, rev, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return fmt.Errorf("[ERROR] resourceApiProxyDeploymentUpdate %s", err.Error())
 		}
-		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentUpdate error redeploying: %s", err.Error())
 	}
 
-	log.Printf("[DEBUG] resourceApiProxyDeploymentUpdate Deployed revision %d of %s", rev, proxy_name)
 	return resourceApiProxyDeploymentRead(d, meta)
 }
 
@@ -244,7 +296,14 @@ func resourceApiProxyDeploymentDelete(d *schema.ResourceData, meta interface{})
 
 	log.Print("[DEBUG] resourceApiProxyDeploymentDelete START")
 
-	client := meta.(*apigee.EdgeClient)
+	if err := checkDeletionProtection(d, fmt.Sprintf("deployment of proxy %s to %s", d.Get("proxy_name").(string), d.Get("env").(string))); err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentDelete %s", err.Error())
+	}
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentDelete %s", err.Error())
+	}
 
 	proxy_name := d.Get("proxy_name").(string)
 	env := d.Get("env").(string)
@@ -260,8 +319,8 @@ func resourceApiProxyDeploymentDelete(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	_, _, err := client.Proxies.Undeploy(proxy_name, env, rev)
-	if err != nil {
+	_, _, err = client.Proxies.Undeploy(proxy_name, env, rev)
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceApiProxyDeploymentDelete error undeploying: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceApiProxyDeploymentDelete error undeploying: %s", err.Error())
 	}
@@ -283,3 +342,17 @@ func getLatestRevision(client *apigee.EdgeClient, proxyName string) (int, error)
 
 	return latestRev, nil
 }
+
+func getDeployedRevision(client *apigee.EdgeClient, proxyName string, env string) (int, error) {
+	deployments, _, err := client.Proxies.GetDeployments(proxyName)
+	if err != nil {
+		return -1, fmt.Errorf("[ERROR] getDeployedRevision error reading deployments: %s", err.Error())
+	}
+
+	rev, found := lastDeployedRevision(deployments.Environments, env)
+	if !found {
+		return -1, fmt.Errorf("[ERROR] getDeployedRevision no revision of %s is deployed to %s", proxyName, env)
+	}
+
+	return int(rev), nil
+}
@@ -0,0 +1,79 @@
+package apigee
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceApiProxyDeploymentV0 is the schema of apigee_api_proxy_deployment while org was deprecated and ignored.
+func resourceApiProxyDeploymentV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"proxy_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"org": {
+				Type:       schema.TypeString,
+				Optional:   true,
+				Deprecated: "org is not required, the value from the provider is used.",
+			},
+			"env": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"revision": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"delay": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"override": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+// resourceApiProxyDeploymentStateUpgradeV0 drops the org of the state.  The deployment was made in the provider's
+// organization whatever org said, the next read stores that one.
+func resourceApiProxyDeploymentStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceApiProxyDeploymentStateUpgradeV0 START")
+
+	delete(rawState, "org")
+
+	return rawState, nil
+}
+
+// resourceApiProxyDeploymentV1 is the schema of apigee_api_proxy_deployment while it was keyed by a UUID.
+func resourceApiProxyDeploymentV1() *schema.Resource {
+
+	r := resourceApiProxyDeploymentV0()
+	r.Schema["org"] = &schema.Schema{Type: schema.TypeString, Optional: true, Computed: true, ForceNew: true}
+
+	return r
+}
+
+// resourceApiProxyDeploymentStateUpgradeV1 moves the ID from a UUID to {proxy_name}_{env}_deployment, the ID
+// deployments are created and imported with.
+func resourceApiProxyDeploymentStateUpgradeV1(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceApiProxyDeploymentStateUpgradeV1 START")
+
+	name, _ := rawState["proxy_name"].(string)
+	env, _ := rawState["env"].(string)
+	if name != "" && env != "" {
+		rawState["id"] = name + "_" + env + "_deployment"
+	}
+
+	return rawState, nil
+}
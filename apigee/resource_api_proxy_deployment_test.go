@@ -3,7 +3,8 @@ package apigee
 import (
 	"fmt"
 	"log"
-	"strings"
+	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -40,6 +41,8 @@ func TestAccProxyDeployment_Updated(t *testing.T) {
 						"apigee_api_proxy_deployment.foo_api_proxy_deployment", "delay", "2"),
 					resource.TestCheckResourceAttr(
 						"apigee_api_proxy_deployment.foo_api_proxy_deployment", "override", "true"),
+					resource.TestCheckResourceAttr(
+						"apigee_api_proxy_deployment.foo_api_proxy_deployment", "wait_for_ready", "true"),
 				),
 			},
 		},
@@ -48,7 +51,7 @@ func TestAccProxyDeployment_Updated(t *testing.T) {
 
 func testAccCheckProxyDeploymentDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := proxyDeploymentDestroyHelper(s, client); err != nil {
 		return err
@@ -58,7 +61,7 @@ func testAccCheckProxyDeploymentDestroy(s *terraform.State) error {
 
 func testAccCheckProxyDeploymentExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := proxyDeploymentExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckProxyDeploymentExists: %s", err)
 			return err
@@ -96,6 +99,7 @@ resource "apigee_api_proxy_deployment" "foo_api_proxy_deployment" {
    revision     = "2"
    delay		= "2"
    override 	= true
+   wait_for_ready = true
 }
 `
 
@@ -111,7 +115,7 @@ func proxyDeploymentDestroyHelper(s *terraform.State, client *apigee.EdgeClient)
 		_, _, err := client.Proxies.GetDeployments("foo_proxy deployment")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving proxy deployment  %+v\n", err)
@@ -139,3 +143,262 @@ func proxyDeploymentExistsHelper(s *terraform.State, client *apigee.EdgeClient,
 	}
 	return nil
 }
+
+func TestProxyDeploymentImport(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testProxyDeploymentImportConfig
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:            config,
+				ResourceName:      "apigee_api_proxy_deployment.foo_api_proxy_deployment",
+				ImportState:       true,
+				ImportStateId:     "foo_proxy_terraformed_test_deployment",
+				ImportStateVerify: true,
+				// Apigee does not tell which revision was deployed before.
+				ImportStateVerifyIgnore: []string{"previous_revision"},
+			},
+		},
+	})
+}
+
+const testProxyDeploymentImportConfig = `
+resource "apigee_api_proxy" "foo_api_proxy" {
+   name         = "foo_proxy_terraformed"
+   bundle       = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+}
+
+resource "apigee_api_proxy_deployment" "foo_api_proxy_deployment" {
+   proxy_name   = "${apigee_api_proxy.foo_api_proxy.name}"
+   env          = "test"
+   revision     = "${apigee_api_proxy.foo_api_proxy.revision}"
+}
+`
+
+func TestProxyDeploymentDeletionProtection(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testProxyDeploymentDeletionProtectionConfig, "true"),
+			},
+			{
+				Config:      provider,
+				ExpectError: regexp.MustCompile("deployment of proxy foo_proxy_terraformed to test has deletion_protection set"),
+			},
+			{
+				// Turning the protection off neither imports nor deploys anything.
+				Config: provider + fmt.Sprintf(testProxyDeploymentDeletionProtectionConfig, "false"),
+				Check:  testCheckFakeDeployed(fake, "apis/foo_proxy_terraformed", "test", 1, 1),
+			},
+			{
+				Config: provider,
+				Check:  testCheckFakeDeployed(fake, "apis/foo_proxy_terraformed", "test", 0),
+			},
+		},
+	})
+}
+
+func TestProxyDeploymentStrategies(t *testing.T) {
+
+	for strategy, deployed := range map[string][]int{
+		"seamless":       {2},
+		"undeploy_first": {2},
+		"side_by_side":   {1, 2},
+	} {
+		t.Run(strategy, func(t *testing.T) {
+
+			fake, server := newFakeEdge(t)
+			provider := fakeEdgeProvider(server, "")
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: provider + fmt.Sprintf(testProxyDeploymentStrategyConfig, "1", strategy),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("apigee_api_proxy_deployment.foo", "previous_revision", ""),
+							testCheckFakeDeployed(fake, "apis/foo_proxy_terraformed", "test", 2, 1),
+						),
+					},
+					{
+						Config: provider + fmt.Sprintf(testProxyDeploymentStrategyConfig, "2", strategy),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("apigee_api_proxy_deployment.foo", "revision", "2"),
+							resource.TestCheckResourceAttr("apigee_api_proxy_deployment.foo", "previous_revision", "1"),
+							testCheckFakeDeployed(fake, "apis/foo_proxy_terraformed", "test", 2, deployed...),
+						),
+					},
+					{
+						// Revisions deployed side by side are not the deployment's to undeploy.
+						PreConfig: func() {
+							fake.mu.Lock()
+							defer fake.mu.Unlock()
+							fake.deployments["apis/foo_proxy_terraformed"]["test"] = []int{2}
+						},
+						Config: provider + fmt.Sprintf(testProxyDeploymentStrategyConfig, "2", strategy),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestProxyDeploymentOverride(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      provider + fmt.Sprintf(testProxyDeploymentOverrideConfig, "1", "override = false\n   strategy = \"seamless\""),
+				ExpectError: regexp.MustCompile(`"override": conflicts with strategy`),
+			},
+			{
+				// override = false is the default, the deployment follows strategy and deploys seamlessly.
+				Config: provider + fmt.Sprintf(testProxyDeploymentOverrideConfig, "1", "override = false"),
+				Check:  resource.TestCheckResourceAttr("apigee_api_proxy_deployment.foo", "override", "false"),
+			},
+			{
+				Config: provider + fmt.Sprintf(testProxyDeploymentOverrideConfig, "2", "override = false"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_api_proxy_deployment.foo", "previous_revision", "1"),
+					testCheckFakeDeployed(fake, "apis/foo_proxy_terraformed", "test", 2, 2),
+				),
+			},
+			{
+				// Leaving override out plans nothing for a state that stores override = false.
+				Config:   provider + fmt.Sprintf(testProxyDeploymentOverrideConfig, "2", ""),
+				PlanOnly: true,
+			},
+			{
+				// override = true deploys seamlessly, the deployed revision is replaced.
+				Config: provider + fmt.Sprintf(testProxyDeploymentOverrideConfig, "1", "override = true"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_api_proxy_deployment.foo", "previous_revision", "2"),
+					testCheckFakeDeployed(fake, "apis/foo_proxy_terraformed", "test", 2, 1),
+				),
+			},
+		},
+	})
+}
+
+// testCheckFakeDeployed checks how many revisions of a proxy or shared flow the fake has and which are deployed.
+func testCheckFakeDeployed(fake *fakeEdge, key string, env string, revisions int, deployed ...int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		fake.mu.Lock()
+		actual := len(fake.revisions(key))
+		fake.mu.Unlock()
+
+		if actual != revisions {
+			return fmt.Errorf("expected %s to have %d revisions, got %d", key, revisions, actual)
+		}
+		if actual := fake.deployed(key, env); fmt.Sprint(actual) != fmt.Sprint(append([]int{}, deployed...)) {
+			return fmt.Errorf("expected revisions %v of %s to be deployed to %s, got %v", deployed, key, env, actual)
+		}
+		return nil
+	}
+}
+
+const testProxyDeploymentDeletionProtectionConfig = `
+resource "apigee_api_proxy" "foo_api_proxy" {
+   name                = "foo_proxy_terraformed"
+   bundle              = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha          = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+   deletion_protection = %[1]s
+}
+
+resource "apigee_api_proxy_deployment" "foo_api_proxy_deployment" {
+   proxy_name          = "${apigee_api_proxy.foo_api_proxy.name}"
+   env                 = "test"
+   revision            = "${apigee_api_proxy.foo_api_proxy.revision}"
+   deletion_protection = %[1]s
+}
+`
+
+const testProxyDeploymentStrategyConfig = `
+resource "apigee_api_proxy_revision" "v1" {
+   proxy_name   = "foo_proxy_terraformed"
+   bundle       = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+}
+
+resource "apigee_api_proxy_revision" "v2" {
+   proxy_name   = "${apigee_api_proxy_revision.v1.proxy_name}"
+   bundle       = "test-fixtures/helloworld_proxy2.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy2.zip")}"
+}
+
+resource "apigee_api_proxy_deployment" "foo" {
+   proxy_name   = "${apigee_api_proxy_revision.v2.proxy_name}"
+   env          = "test"
+   revision     = "%s"
+   strategy     = "%s"
+}
+`
+
+const testProxyDeploymentOverrideConfig = `
+resource "apigee_api_proxy_revision" "v1" {
+   proxy_name   = "foo_proxy_terraformed"
+   bundle       = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+}
+
+resource "apigee_api_proxy_revision" "v2" {
+   proxy_name   = "${apigee_api_proxy_revision.v1.proxy_name}"
+   bundle       = "test-fixtures/helloworld_proxy2.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy2.zip")}"
+}
+
+resource "apigee_api_proxy_deployment" "foo" {
+   proxy_name   = "${apigee_api_proxy_revision.v2.proxy_name}"
+   env          = "test"
+   revision     = "%s"
+   %s
+}
+`
+
+func TestResourceApiProxyDeploymentStateUpgradeV0(t *testing.T) {
+
+	actual, err := resourceApiProxyDeploymentStateUpgradeV0(map[string]interface{}{
+		"proxy_name": "helloworld",
+		"org":        "whatever-org",
+		"env":        "test",
+		"revision":   "1",
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]interface{}{"proxy_name": "helloworld", "env": "test", "revision": "1"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestResourceApiProxyDeploymentStateUpgradeV1(t *testing.T) {
+
+	actual, err := resourceApiProxyDeploymentStateUpgradeV1(map[string]interface{}{
+		"id":         "6f1c2b3a-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		"proxy_name": "helloworld",
+		"env":        "test",
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual["id"] != "helloworld_test_deployment" {
+		t.Fatalf("expected the ID to be helloworld_test_deployment, got %v", actual["id"])
+	}
+}
@@ -0,0 +1,50 @@
+package apigee
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceApiProxyV0 is the schema of apigee_api_proxy while it was keyed by the UUID it was created with.
+func resourceApiProxyV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"bundle": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"bundle_sha": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			//revision_sha is used as a workaround for: https://github.com/hashicorp/terraform/issues/15857
+			"revision_sha": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"revision": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// resourceApiProxyStateUpgradeV0 moves the ID from the UUID the proxy was created with to its name, the ID
+// proxies are created and imported with.
+func resourceApiProxyStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceApiProxyStateUpgradeV0 START")
+
+	if name, ok := rawState["name"].(string); ok && name != "" {
+		rawState["id"] = name
+	}
+
+	return rawState, nil
+}
@@ -0,0 +1,194 @@
+package apigee
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/zambien/go-apigee-edge"
+)
+
+func resourceApiProxyRevision() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceApiProxyRevisionCreate,
+		Read:   resourceApiProxyRevisionRead,
+		Delete: resourceApiProxyRevisionDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceApiProxyRevisionImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"proxy_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			//bundle is kept as the bundleSha of the file and read back from the revision, so imported revisions and
+			//revisions edited outside of terraform compare against the file's contents.
+			"bundle": {
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				StateFunc: bundleStateFunc,
+			},
+			"bundle_sha": {
+				Type:       schema.TypeString,
+				Optional:   true,
+				ForceNew:   true,
+				Deprecated: "bundle is compared by the contents of the file, bundle_sha is not needed",
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return true
+				},
+			},
+			"revision": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceApiProxyRevisionCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Print("[DEBUG] resourceApiProxyRevisionCreate START")
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyRevisionCreate %s", err.Error())
+	}
+
+	proxyRev, _, err := client.Proxies.Import(d.Get("proxy_name").(string), d.Get("bundle").(string))
+	if err != nil {
+		log.Printf("[ERROR] resourceApiProxyRevisionCreate error importing api_proxy revision: %s", err.Error())
+		return fmt.Errorf("[ERROR] resourceApiProxyRevisionCreate error importing api_proxy revision: %s", err.Error())
+	}
+
+	//The ID is the import ID so that created and imported revisions are alike.
+	d.SetId(fmt.Sprintf("%s_%s", d.Get("proxy_name").(string), proxyRev.Revision.String()))
+	d.Set("revision", proxyRev.Revision.String())
+
+	log.Printf("[DEBUG] resourceApiProxyRevisionCreate imported revision %d of %s", proxyRev.Revision, d.Get("proxy_name").(string))
+	return resourceApiProxyRevisionRead(d, meta)
+}
+
+func resourceApiProxyRevisionImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	log.Print("[DEBUG] resourceApiProxyRevisionImport START")
+
+	importOrg(d)
+
+	splits := strings.Split(d.Id(), "_")
+	if len(splits) < 2 {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERR] Wrong format of resource: %s. Please follow '{proxy_name}_{revision}'", d.Id())
+	}
+	revision := splits[len(splits)-1]
+	if _, err := strconv.Atoi(revision); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERR] Wrong format of resource: %s. Please follow '{proxy_name}_{revision}'", d.Id())
+	}
+
+	d.Set("proxy_name", d.Id()[:len(d.Id())-len(revision)-1])
+	d.Set("revision", revision)
+
+	if err := resourceApiProxyRevisionRead(d, meta); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceApiProxyRevisionImport error reading revision: %s", err.Error())
+	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceApiProxyRevisionImport revision %s of %s does not exist", revision, d.Get("proxy_name").(string))
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceApiProxyRevisionRead(d *schema.ResourceData, meta interface{}) error {
+	log.Print("[DEBUG] resourceApiProxyRevisionRead START")
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyRevisionRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
+
+	proxy, _, err := client.Proxies.Get(d.Get("proxy_name").(string))
+	if err != nil {
+		log.Printf("[ERROR] resourceApiProxyRevisionRead error reading proxies: %s", err.Error())
+		if isNotFound(err) {
+			log.Printf("[DEBUG] resourceApiProxyRevisionRead 404 encountered.  Removing state for proxy: %#v", d.Get("proxy_name").(string))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("[ERROR] resourceApiProxyRevisionRead error reading proxies: %s", err.Error())
+	}
+
+	for _, rev := range proxy.Revisions {
+		if rev.String() == d.Get("revision").(string) {
+			bundle, err := exportRevision(client, proxiesPath, proxy.Name, rev)
+			if err != nil {
+				log.Printf("[ERROR] resourceApiProxyRevisionRead error exporting revision %s: %s", rev, err.Error())
+				return fmt.Errorf("[ERROR] resourceApiProxyRevisionRead error exporting revision %s: %s", rev, err.Error())
+			}
+			revisionSha, err := bundleSha(bundle)
+			if err != nil {
+				return fmt.Errorf("[ERROR] resourceApiProxyRevisionRead error hashing revision %s: %s", rev, err.Error())
+			}
+
+			d.Set("proxy_name", proxy.Name)
+			d.Set("bundle", revisionSha)
+			return nil
+		}
+	}
+
+	log.Printf("[DEBUG] resourceApiProxyRevisionRead revision %#v not found.  Removing state for proxy: %#v", d.Get("revision").(string), d.Get("proxy_name").(string))
+	d.SetId("")
+	return nil
+}
+
+func resourceApiProxyRevisionDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Print("[DEBUG] resourceApiProxyRevisionDelete START")
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceApiProxyRevisionDelete %s", err.Error())
+	}
+
+	proxyName := d.Get("proxy_name").(string)
+	revInt, _ := strconv.Atoi(d.Get("revision").(string))
+	rev := apigee.Revision(revInt)
+
+	proxy, _, err := client.Proxies.Get(proxyName)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("[ERROR] resourceApiProxyRevisionDelete error reading proxies: %s", err.Error())
+	}
+
+	//Apigee will not delete the only revision of a proxy.  It is left in place rather than deleting the proxy, which
+	//may be managed elsewhere together with its deployments.
+	if len(proxy.Revisions) == 1 && proxy.Revisions[0] == rev {
+		log.Printf("[WARN] resourceApiProxyRevisionDelete revision %d is the only revision of %s and is left in place", rev, proxyName)
+		return nil
+	}
+
+	if _, _, err := client.Proxies.DeleteRevision(proxyName, rev); err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		log.Printf("[ERROR] resourceApiProxyRevisionDelete error deleting revision: %s", err.Error())
+		return fmt.Errorf("[ERROR] resourceApiProxyRevisionDelete error deleting revision: %s", err.Error())
+	}
+
+	log.Printf("[DEBUG] resourceApiProxyRevisionDelete deleted revision %d of %s", rev, proxyName)
+	return nil
+}
@@ -0,0 +1,208 @@
+package apigee
+
+import (
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/zambien/go-apigee-edge"
+)
+
+func TestAccProxyRevision_Pinned(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckProxyRevisionDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccCheckProxyRevisionConfigRequired,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckProxyRevisionExists("apigee_api_proxy_revision.foo_api_proxy_revision_1"),
+					resource.TestCheckResourceAttr(
+						"apigee_api_proxy_revision.foo_api_proxy_revision_1", "proxy_name", "foo_proxy_revision_terraformed"),
+					resource.TestCheckResourceAttr(
+						"apigee_api_proxy_revision.foo_api_proxy_revision_1", "revision", "1"),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccCheckProxyRevisionConfigUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckProxyRevisionExists("apigee_api_proxy_revision.foo_api_proxy_revision_1"),
+					testAccCheckProxyRevisionExists("apigee_api_proxy_revision.foo_api_proxy_revision_2"),
+					resource.TestCheckResourceAttr(
+						"apigee_api_proxy_revision.foo_api_proxy_revision_1", "revision", "1"),
+					resource.TestCheckResourceAttr(
+						"apigee_api_proxy_revision.foo_api_proxy_revision_2", "revision", "2"),
+					resource.TestCheckResourceAttr(
+						"apigee_api_proxy_deployment.foo_api_proxy_deployment", "revision", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckProxyRevisionDestroy(s *terraform.State) error {
+
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
+
+	for _, r := range s.RootModule().Resources {
+		if r.Type != "apigee_api_proxy_revision" {
+			continue
+		}
+
+		// The last revision of the proxy is left in place.
+		proxy, _, err := client.Proxies.Get(r.Primary.Attributes["proxy_name"])
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("Received an error retrieving proxy  %+v\n", err)
+		}
+		if len(proxy.Revisions) > 1 {
+			return fmt.Errorf("Proxy %s still has revisions %v", proxy.Name, proxy.Revisions)
+		}
+		if _, _, err := client.Proxies.Delete(proxy.Name); err != nil {
+			return fmt.Errorf("Received an error deleting proxy %+v\n", err)
+		}
+	}
+	return nil
+}
+
+func testAccCheckProxyRevisionExists(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
+		if err := proxyRevisionExistsHelper(s, client, n); err != nil {
+			log.Printf("Error in testAccCheckProxyRevisionExists: %s", err)
+			return err
+		}
+		return nil
+	}
+}
+
+const testAccCheckProxyRevisionConfigRequired = `
+resource "apigee_api_proxy_revision" "foo_api_proxy_revision_1" {
+   proxy_name   = "foo_proxy_revision_terraformed"
+   bundle       = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+}
+`
+
+const testAccCheckProxyRevisionConfigUpdated = `
+resource "apigee_api_proxy_revision" "foo_api_proxy_revision_1" {
+   proxy_name   = "foo_proxy_revision_terraformed"
+   bundle       = "test-fixtures/helloworld_proxy.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+}
+
+resource "apigee_api_proxy_revision" "foo_api_proxy_revision_2" {
+   proxy_name   = "${apigee_api_proxy_revision.foo_api_proxy_revision_1.proxy_name}"
+   bundle       = "test-fixtures/helloworld_proxy2.zip"
+   bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy2.zip")}"
+}
+
+resource "apigee_api_proxy_deployment" "foo_api_proxy_deployment" {
+   proxy_name   = "${apigee_api_proxy_revision.foo_api_proxy_revision_2.proxy_name}"
+   env          = "test"
+   revision     = "${apigee_api_proxy_revision.foo_api_proxy_revision_2.revision}"
+}
+`
+
+func proxyRevisionExistsHelper(s *terraform.State, client *apigee.EdgeClient, n string) error {
+
+	r, ok := s.RootModule().Resources[n]
+	if !ok {
+		return fmt.Errorf("Not found: %s", n)
+	}
+
+	if r.Primary.ID == "" {
+		return fmt.Errorf("No proxy revision ID is set")
+	}
+
+	proxyData, _, err := client.Proxies.Get(r.Primary.Attributes["proxy_name"])
+	if err != nil {
+		return fmt.Errorf("Received an error retrieving proxy  %+v\n", err)
+	}
+
+	for _, rev := range proxyData.Revisions {
+		if rev.String() == r.Primary.Attributes["revision"] {
+			log.Printf("Created proxy revision: %s %s", proxyData.Name, rev)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Revision %s of proxy %s does not exist", r.Primary.Attributes["revision"], proxyData.Name)
+}
+
+func TestProxyRevisionImport(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testAccCheckProxyRevisionConfigRequired
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:            config,
+				ResourceName:      "apigee_api_proxy_revision.foo_api_proxy_revision_1",
+				ImportState:       true,
+				ImportStateId:     "foo_proxy_revision_terraformed_1",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestProxyRevisionDeleteLast(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: func(*terraform.State) error {
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if revisions := fake.revisions("apis/foo_proxy_revision_terraformed"); len(revisions) != 1 {
+				return fmt.Errorf("expected the last revision of foo_proxy_revision_terraformed to be left in place, got %v", revisions)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fakeEdgeProvider(server, "") + testAccCheckProxyRevisionConfigRequired,
+			},
+		},
+	})
+}
+
+func TestProxyRevisionDrift(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testAccCheckProxyRevisionConfigRequired
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				// A revision edited in the management UI no longer matches the bundle and is replaced.
+				PreConfig: func() {
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					key := "apis/foo_proxy_revision_terraformed"
+					fake.bundles[key][0] = testEditBundle(t, fake.bundles[key][0], "apiproxy/policies/add-cors.xml", "add-cors", "add-cors-edited")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -1,14 +1,18 @@
 package apigee
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/zambien/go-apigee-edge"
+	"io/ioutil"
 	"log"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAccProxy_Updated(t *testing.T) {
@@ -78,7 +82,7 @@ func TestAccProxy_FailToDeleteWhenDeploymentExists(t *testing.T) {
 
 func testAccCheckProxyDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := proxyDestroyHelper(s, client); err != nil {
 		return err
@@ -88,7 +92,7 @@ func testAccCheckProxyDestroy(s *terraform.State) error {
 
 func testAccCheckProxyExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := proxyExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckProxyExists: %s", err)
 			return err
@@ -118,6 +122,10 @@ resource "apigee_api_proxy" "foo_api_proxy" {
    name  		= "foo_proxy_terraformed_delete_test"
    bundle       = "test-fixtures/helloworld_proxy.zip"
    bundle_sha   = "${filebase64sha256("test-fixtures/helloworld_proxy.zip")}"
+
+   timeouts {
+      delete = "20s"
+   }
 }
 `
 
@@ -136,7 +144,7 @@ func proxyDestroyHelper(s *terraform.State, client *apigee.EdgeClient) error {
 		_, _, err := client.Proxies.Get(r.Primary.Attributes["name"])
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving proxy  %+v\n", err)
@@ -167,7 +175,7 @@ func proxyExistsHelper(s *terraform.State, client *apigee.EdgeClient, name strin
 
 func deployProxy(t *testing.T, proxyName string) func() {
 	return func() {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		_, _, err := client.Proxies.Deploy(proxyName, "test", 1, 1, false)
 		if err != nil {
 			t.Logf("[ERROR] Could not deploy proxy: %s, %s", proxyName, err)
@@ -179,7 +187,7 @@ func deployProxy(t *testing.T, proxyName string) func() {
 
 func undeployProxy(t *testing.T, proxyName string) func() {
 	return func() {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		_, _, err := client.Proxies.Undeploy(proxyName, "test", 1)
 		if err != nil {
 			t.Logf("[ERROR] Could not undeploy proxy: %s, %s", proxyName, err)
@@ -188,3 +196,157 @@ func undeployProxy(t *testing.T, proxyName string) func() {
 		}
 	}
 }
+
+func TestProxyImport(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testAccCheckProxyConfigRequired
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:            config,
+				ResourceName:      "apigee_api_proxy.foo_api_proxy",
+				ImportState:       true,
+				ImportStateId:     "foo_proxy_terraformed",
+				ImportStateVerify: true,
+				// The bundle is not read back, revision_sha tells whether the proxy changed.
+				ImportStateVerifyIgnore: []string{"bundle", "bundle_sha"},
+			},
+		},
+	})
+}
+
+func TestProxyDrift(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckProxyConfigRequired,
+			},
+			{
+				// Changing the base path of the deployed revision in the management UI is drift.
+				PreConfig: func() {
+					bundle, err := ioutil.ReadFile("test-fixtures/helloworld_proxy.zip")
+					if err != nil {
+						t.Fatalf("err: %s", err)
+					}
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					fake.bundles["apis/foo_proxy_terraformed"][0] = testEditBundle(t, bundle, "apiproxy/helloworld.xml", "/v0/hello", "/v1/hello")
+				},
+				Config:             provider + testAccCheckProxyConfigRequired,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:      provider + testProxyInvalidBundleConfig,
+				ExpectError: regexp.MustCompile("error hashing bundle bundle.go"),
+			},
+		},
+	})
+}
+
+func TestProxyDeleteConflict(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+	var start time.Time
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckProxyConfigRequired,
+			},
+			{
+				// A proxy used by a product stays so until someone acts, the delete fails without waiting.
+				PreConfig: func() {
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					fake.refuse = map[string]string{"DELETE apis/foo_proxy_terraformed": "keymanagement.service.ApiProductReferencesProxy"}
+					start = time.Now()
+				},
+				Config:      provider,
+				ExpectError: regexp.MustCompile("unable to delete ApiProxy: .* 409"),
+			},
+			{
+				// An undeploy still in progress is waited for.
+				PreConfig: func() {
+					if elapsed := time.Since(start); elapsed > 5*time.Second {
+						t.Fatalf("delete retried for %s", elapsed)
+					}
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					fake.refuse = map[string]string{"DELETE apis/foo_proxy_terraformed": "messaging.config.beans.ApplicationHasDeployments"}
+					time.AfterFunc(time.Second, func() {
+						fake.mu.Lock()
+						defer fake.mu.Unlock()
+						fake.refuse = nil
+					})
+				},
+				Config: provider,
+				Check: func(*terraform.State) error {
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					if fake.bundles["apis/foo_proxy_terraformed"] != nil {
+						return fmt.Errorf("proxy foo_proxy_terraformed was not deleted")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// testEditBundle returns bundle with old replaced by new in one of its files.
+func testEditBundle(t *testing.T, bundle []byte, name string, old string, new string) []byte {
+
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	files := map[string]string{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		contents, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(contents)
+	}
+	files[name] = strings.Replace(files[name], old, new, -1)
+
+	return testBundle(t, time.Now(), files)
+}
+
+const testProxyInvalidBundleConfig = `
+resource "apigee_api_proxy" "foo_api_proxy" {
+   name         = "foo_proxy_terraformed"
+   bundle       = "bundle.go"
+   bundle_sha   = "${filebase64sha256("bundle.go")}"
+}
+`
+
+func TestResourceApiProxyStateUpgradeV0(t *testing.T) {
+
+	actual, err := resourceApiProxyStateUpgradeV0(map[string]interface{}{
+		"id":   "6f1c2b3a-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		"name": "helloworld",
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual["id"] != "helloworld" {
+		t.Fatalf("expected the ID to be the proxy name, got %v", actual["id"])
+	}
+}
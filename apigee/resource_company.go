@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/zambien/go-apigee-edge"
 	"log"
-	"strings"
+	"time"
 )
 
 func resourceCompany() *schema.Resource {
@@ -16,7 +17,21 @@ func resourceCompany() *schema.Resource {
 		Update: resourceCompanyUpdate,
 		Delete: resourceCompanyDelete,
 
+		CustomizeDiff: customizeDefaultAttributes,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -31,6 +46,27 @@ func resourceCompany() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"attributes_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      attributesAuthoritative,
+				ValidateFunc: validation.StringInSlice([]string{attributesAuthoritative, attributesAdditive}, false),
+			},
+			"ignore_attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"default_attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+			//force_destroy allows deleting a company that still has apps.  Deleting a company deletes its apps and keys.
+			"force_destroy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"apps": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -48,12 +84,15 @@ func resourceCompanyCreate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyCreate %s", err.Error())
+	}
 
 	u1, _ := uuid.NewV4()
 	d.SetId(u1.String())
 
-	CompanyData, err := setCompanyData(d)
+	CompanyData, err := setCompanyData(d, meta, nil)
 	if err != nil {
 		log.Printf("[ERROR] resourceCompanyCreate error in setCompanyData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceCompanyCreate error in setCompanyData: %s", err.Error())
@@ -71,12 +110,16 @@ func resourceCompanyCreate(d *schema.ResourceData, meta interface{}) error {
 func resourceCompanyRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyRead START")
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	CompanyData, _, err := client.Companies.Get(d.Get("name").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceCompanyRead error getting companies: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceCompanyRead 404 encountered.  Removing state for developer: %#v", d.Get("name").(string))
 			d.SetId("")
 			return nil
@@ -95,7 +138,8 @@ func resourceCompanyRead(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	d.Set("name", CompanyData.Name)
-	d.Set("attributes", CompanyData.Attributes)
+	d.Set("attributes", flattenAttributes(CompanyData.Attributes, d, meta))
+	d.Set("default_attributes", flattenDefaultAttributes(CompanyData.Attributes, d, meta))
 	d.Set("apps", apps)
 	d.Set("status", CompanyData.Status)
 
@@ -106,9 +150,22 @@ func resourceCompanyUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyUpdate %s", err.Error())
+	}
+
+	var current []apigee.Attribute
+	if preservesAttributes(d) {
+		existing, _, err := client.Companies.Get(d.Get("name").(string))
+		if err != nil {
+			log.Printf("[ERROR] resourceCompanyUpdate error reading company attributes: %s", err.Error())
+			return fmt.Errorf("[ERROR] resourceCompanyUpdate error reading company attributes: %s", err.Error())
+		}
+		current = existing.Attributes
+	}
 
-	CompanyData, err := setCompanyData(d)
+	CompanyData, err := setCompanyData(d, meta, current)
 	if err != nil {
 		log.Printf("[ERROR] resourceCompanyUpdate error in setCompanyData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceCompanyUpdate error in setCompanyData: %s", err.Error())
@@ -127,10 +184,27 @@ func resourceCompanyDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyDelete START")
 
-	client := meta.(*apigee.EdgeClient)
-
-	_, err := client.Companies.Delete(d.Get("name").(string))
+	client, err := orgClient(d, meta)
 	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyDelete %s", err.Error())
+	}
+
+	if !d.Get("force_destroy").(bool) {
+		CompanyData, _, err := client.Companies.Get(d.Get("name").(string))
+		if err != nil && !isNotFound(err) {
+			log.Printf("[ERROR] resourceCompanyDelete error reading company apps: %s", err.Error())
+			return fmt.Errorf("[ERROR] resourceCompanyDelete error reading company apps: %s", err.Error())
+		}
+		if err == nil {
+			d.Set("apps", flattenStringList(CompanyData.Apps))
+			if err := checkForceDestroy(d, "company", d.Get("name").(string), CompanyData.Apps); err != nil {
+				return fmt.Errorf("[ERROR] resourceCompanyDelete %s", err.Error())
+			}
+		}
+	}
+
+	_, err = client.Companies.Delete(d.Get("name").(string))
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceCompanyDelete error in developer delete: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceCompanyDelete error in developer delete: %s", err.Error())
 	}
@@ -138,7 +212,7 @@ func resourceCompanyDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func setCompanyData(d *schema.ResourceData) (apigee.Company, error) {
+func setCompanyData(d *schema.ResourceData, meta interface{}, current []apigee.Attribute) (apigee.Company, error) {
 
 	log.Print("[DEBUG] setCompanyData START")
 
@@ -146,10 +220,7 @@ func setCompanyData(d *schema.ResourceData) (apigee.Company, error) {
 		d.Set("display_name", d.Get("name"))
 	}
 
-	attributes := []apigee.Attribute{}
-	if d.Get("attributes") != nil {
-		attributes = attributesFromMap(d.Get("attributes").(map[string]interface{}))
-	}
+	attributes := attributesWithDefaults(d, meta, current)
 
 	Company := apigee.Company{
 		Name:        d.Get("name").(string),
@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/zambien/go-apigee-edge"
 	"log"
-	"strings"
+	"time"
 )
 
 func resourceCompanyApp() *schema.Resource {
@@ -16,7 +17,21 @@ func resourceCompanyApp() *schema.Resource {
 		Update: resourceCompanyAppUpdate,
 		Delete: resourceCompanyAppDelete,
 
+		CustomizeDiff: customizeDefaultAttributes,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"company_name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -28,7 +43,7 @@ func resourceCompanyApp() *schema.Resource {
 				ForceNew: true,
 			},
 			"api_products": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
@@ -36,6 +51,21 @@ func resourceCompanyApp() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"attributes_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      attributesAuthoritative,
+				ValidateFunc: validation.StringInSlice([]string{attributesAuthoritative, attributesAdditive}, false),
+			},
+			"ignore_attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"default_attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
 			"test": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -97,7 +127,7 @@ func resourceCompanyApp() *schema.Resource {
 				},
 			},
 			"scopes": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
@@ -129,18 +159,21 @@ func resourceCompanyAppCreate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyAppCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyAppCreate %s", err.Error())
+	}
 
 	u1, _ := uuid.NewV4()
 	d.SetId(u1.String())
 
-	CompanyAppData, err := setCompanyAppData(d)
+	CompanyAppData, err := setCompanyAppData(d, meta, nil)
 	if err != nil {
 		log.Printf("[ERROR] resourceCompanyAppCreate error in setCompanyAppData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceCompanyAppCreate error in setCompanyAppData: %s", err.Error())
 	}
 
-	log.Printf("[DEBUG] resourceCompanyAppCreate sending object: %+v\n", CompanyAppData)
+	log.Printf("[DEBUG] resourceCompanyAppCreate sending object: %s\n", redactedObject(CompanyAppData))
 
 	_, _, e := client.CompanyApps.Create(d.Get("company_name").(string), CompanyAppData)
 	if e != nil {
@@ -154,12 +187,16 @@ func resourceCompanyAppCreate(d *schema.ResourceData, meta interface{}) error {
 func resourceCompanyAppRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyAppRead START")
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyAppRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	CompanyAppData, _, err := client.CompanyApps.Get(d.Get("company_name").(string), d.Get("name").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceCompanyAppRead error getting company apps: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceCompanyAppRead 404 encountered.  Removing state for company app: %#v", d.Get("name").(string))
 			d.SetId("")
 			return nil
@@ -169,7 +206,7 @@ func resourceCompanyAppRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	log.Printf("[DEBUG] resourceCompanyAppRead CompanyAppData: %+v\n", CompanyAppData)
+	log.Printf("[DEBUG] resourceCompanyAppRead CompanyAppData: %s\n", redactedObject(CompanyAppData))
 
 	//Scopes and apiProducts are tricky.  These actually result in an array which will always have
 	//one element unless an outside API is called.
@@ -179,20 +216,14 @@ func resourceCompanyAppRead(d *schema.ResourceData, meta interface{}) error {
 	//TBD: credentials complex list.  See comments in resource_developer_app.go
 	d.Set("credentials", make([]interface{}, 0))
 
-	//Apigee does not return products in the order you send them
 	//Get the most recent api products from the last credentials set
-	oldApiProducts := getStringList("api_products", d)
-	newApiProducts := apiProductsListFromCredentials(CompanyAppData.Credentials[len(CompanyAppData.Credentials)-1].ApiProducts)
-
-	if !arraySortedEqual(oldApiProducts, newApiProducts) {
-		d.Set("api_products", newApiProducts)
-	} else {
-		d.Set("api_products", oldApiProducts)
-	}
+	apiProducts := apiProductsListFromCredentials(CompanyAppData.Credentials[len(CompanyAppData.Credentials)-1].ApiProducts)
+	d.Set("api_products", apiProducts)
 
 	d.Set("test","tester")
 	d.Set("name", CompanyAppData.Name)
-	d.Set("attributes", CompanyAppData.Attributes)
+	d.Set("attributes", flattenAttributes(CompanyAppData.Attributes, d, meta))
+	d.Set("default_attributes", flattenDefaultAttributes(CompanyAppData.Attributes, d, meta))
 	d.Set("scopes", scopes)
 	d.Set("callback_url", CompanyAppData.CallbackUrl)
 	d.Set("app_id", CompanyAppData.AppId)
@@ -208,9 +239,22 @@ func resourceCompanyAppUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyAppUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyAppUpdate %s", err.Error())
+	}
+
+	var current []apigee.Attribute
+	if preservesAttributes(d) {
+		existing, _, err := client.CompanyApps.Get(d.Get("company_name").(string), d.Get("name").(string))
+		if err != nil {
+			log.Printf("[ERROR] resourceCompanyAppUpdate error reading company app attributes: %s", err.Error())
+			return fmt.Errorf("[ERROR] resourceCompanyAppUpdate error reading company app attributes: %s", err.Error())
+		}
+		current = existing.Attributes
+	}
 
-	CompanyAppData, err := setCompanyAppData(d)
+	CompanyAppData, err := setCompanyAppData(d, meta, current)
 	if err != nil {
 		log.Printf("[ERROR] resourceCompanyAppUpdate error in setCompanyAppData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceCompanyAppUpdate error in setCompanyAppData: %s", err.Error())
@@ -229,10 +273,13 @@ func resourceCompanyAppDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceCompanyAppDelete START")
 
-	client := meta.(*apigee.EdgeClient)
-
-	_, err := client.CompanyApps.Delete(d.Get("company_name").(string), d.Get("name").(string))
+	client, err := orgClient(d, meta)
 	if err != nil {
+		return fmt.Errorf("[ERROR] resourceCompanyAppDelete %s", err.Error())
+	}
+
+	_, err = client.CompanyApps.Delete(d.Get("company_name").(string), d.Get("name").(string))
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceCompanyAppDelete error in company app delete: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceCompanyAppDelete error in company app delete: %s", err.Error())
 	}
@@ -240,7 +287,7 @@ func resourceCompanyAppDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func setCompanyAppData(d *schema.ResourceData) (apigee.CompanyApp, error) {
+func setCompanyAppData(d *schema.ResourceData, meta interface{}, current []apigee.Attribute) (apigee.CompanyApp, error) {
 
 	log.Print("[DEBUG] setCompanyAppData START")
 
@@ -255,10 +302,7 @@ func setCompanyAppData(d *schema.ResourceData) (apigee.CompanyApp, error) {
 	}
 	log.Printf("[DEBUG] setCompanyAppData scopes: %+v\n", scopes)
 
-	attributes := []apigee.Attribute{}
-	if d.Get("attributes") != nil {
-		attributes = attributesFromMap(d.Get("attributes").(map[string]interface{}))
-	}
+	attributes := attributesWithDefaults(d, meta, current)
 
 	CompanyApp := apigee.CompanyApp{
 		Name:        d.Get("name").(string),
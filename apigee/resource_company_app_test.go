@@ -6,7 +6,6 @@ import (
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/zambien/go-apigee-edge"
 	"log"
-	"strings"
 	"testing"
 )
 
@@ -34,10 +33,10 @@ func TestAccCompanyApp_Updated(t *testing.T) {
 						"apigee_company_app.foo_company_app", "name", "foo_company_app_name_updated"),
 					resource.TestCheckResourceAttr(
 						"apigee_company_app.foo_company_app", "company_name", "foo_company"),
-					resource.TestCheckResourceAttr(
-						"apigee_company_app.foo_company_app", "api_products.0", "foo_product"),
-					resource.TestCheckResourceAttr(
-						"apigee_company_app.foo_company_app", "scopes.0", "READ"),
+					testCheckResourceSetAttr(
+						"apigee_company_app.foo_company_app", "api_products", "foo_product"),
+					testCheckResourceSetAttr(
+						"apigee_company_app.foo_company_app", "scopes", "READ"),
 					resource.TestCheckResourceAttr(
 						"apigee_company_app.foo_company_app", "callback_url", "https://www.google.com"),
 				),
@@ -48,7 +47,7 @@ func TestAccCompanyApp_Updated(t *testing.T) {
 
 func testAccCheckCompanyAppDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := companyAppDestroyHelper(s, client); err != nil {
 		return err
@@ -58,7 +57,7 @@ func testAccCheckCompanyAppDestroy(s *terraform.State) error {
 
 func testAccCheckCompanyAppExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := companyAppExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckCompanyAppExists: %s", err)
 			return err
@@ -110,7 +109,7 @@ func companyAppDestroyHelper(s *terraform.State, client *apigee.EdgeClient) erro
 		_, _, err := client.CompanyApps.Get("foo_company", "foo_company_app")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving company app: %+v\n", err)
@@ -6,7 +6,7 @@ import (
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/zambien/go-apigee-edge"
 	"log"
-	"strings"
+	"regexp"
 	"testing"
 )
 
@@ -42,7 +42,7 @@ func TestAccCompany_Updated(t *testing.T) {
 
 func testAccCheckCompanyDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := companyDestroyHelper(s, client); err != nil {
 		return err
@@ -52,7 +52,7 @@ func testAccCheckCompanyDestroy(s *terraform.State) error {
 
 func testAccCheckCompanyExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := companyExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckCompanyExists: %s", err)
 			return err
@@ -77,6 +77,44 @@ resource "apigee_company" "foo_company" {
 }
 `
 
+func TestCompanyForceDestroy(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testCompanyForceDestroyConfig, "false"),
+			},
+			{
+				// Apps created outside of Terraform keep the company from being destroyed.
+				PreConfig: func() {
+					fake.seed("companies/foo-company/apps/partner-app", `{"name": "partner-app"}`)
+					fake.seed("companies/foo-company/apps/portal-app", `{"name": "portal-app"}`)
+				},
+				Config:      provider,
+				ExpectError: regexp.MustCompile("company foo-company still has apps partner-app, portal-app"),
+			},
+			{
+				Config: provider + fmt.Sprintf(testCompanyForceDestroyConfig, "true"),
+			},
+			{
+				Config: provider,
+				Check:  testCheckFakeEntities(fake),
+			},
+		},
+	})
+}
+
+const testCompanyForceDestroyConfig = `
+resource "apigee_company" "foo" {
+   name          = "foo-company"
+   force_destroy = %s
+}
+`
+
 func companyDestroyHelper(s *terraform.State, client *apigee.EdgeClient) error {
 
 	for _, r := range s.RootModule().Resources {
@@ -89,7 +127,7 @@ func companyDestroyHelper(s *terraform.State, client *apigee.EdgeClient) error {
 		_, _, err := client.Companies.Get("foo_company")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving company  %+v\n", err)
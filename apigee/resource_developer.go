@@ -3,10 +3,12 @@ package apigee
 import (
 	"fmt"
 	"log"
-	"strings"
+	"path"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/zambien/go-apigee-edge"
 )
 
@@ -17,11 +19,24 @@ func resourceDeveloper() *schema.Resource {
 		Update: resourceDeveloperUpdate,
 		Delete: resourceDeveloperDelete,
 
+		CustomizeDiff: customizeDefaultAttributes,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"email": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			"first_name": {
 				Type:     schema.TypeString,
@@ -39,6 +54,27 @@ func resourceDeveloper() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"attributes_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      attributesAuthoritative,
+				ValidateFunc: validation.StringInSlice([]string{attributesAuthoritative, attributesAdditive}, false),
+			},
+			"ignore_attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"default_attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+			//force_destroy allows deleting a developer who still has apps.  Deleting a developer deletes their apps and keys.
+			"force_destroy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"apps": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -60,12 +96,15 @@ func resourceDeveloperCreate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceDeveloperCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperCreate %s", err.Error())
+	}
 
 	u1, _ := uuid.NewV4()
 	d.SetId(u1.String())
 
-	DeveloperData, err := setDeveloperData(d)
+	DeveloperData, err := setDeveloperData(d, meta, nil)
 	if err != nil {
 		log.Printf("[ERROR] resourceDeveloperCreate error in setDeveloperData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperCreate error in setDeveloperData: %s", err.Error())
@@ -83,12 +122,16 @@ func resourceDeveloperCreate(d *schema.ResourceData, meta interface{}) error {
 func resourceDeveloperRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceDeveloperRead START")
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	DeveloperData, _, err := client.Developers.Get(d.Get("email").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceDeveloperRead error getting developers: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceDeveloperRead 404 encountered.  Removing state for developer: %#v", d.Get("email").(string))
 			d.SetId("")
 			return nil
@@ -104,7 +147,8 @@ func resourceDeveloperRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("first_name", DeveloperData.FirstName)
 	d.Set("last_name", DeveloperData.LastName)
 	d.Set("user_name", DeveloperData.UserName)
-	d.Set("attributes", DeveloperData.Attributes)
+	d.Set("attributes", flattenAttributes(DeveloperData.Attributes, d, meta))
+	d.Set("default_attributes", flattenDefaultAttributes(DeveloperData.Attributes, d, meta))
 	d.Set("apps", apps)
 	d.Set("developer_id", DeveloperData.DeveloperId)
 	d.Set("status", DeveloperData.Status)
@@ -116,15 +160,31 @@ func resourceDeveloperUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceDeveloperUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperUpdate %s", err.Error())
+	}
+
+	//The developer is addressed by the email it has before the update, which also changes the email.
+	oldEmail, _ := d.GetChange("email")
 
-	DeveloperData, err := setDeveloperData(d)
+	var current []apigee.Attribute
+	if preservesAttributes(d) {
+		existing, _, err := client.Developers.Get(oldEmail.(string))
+		if err != nil {
+			log.Printf("[ERROR] resourceDeveloperUpdate error reading developer attributes: %s", err.Error())
+			return fmt.Errorf("[ERROR] resourceDeveloperUpdate error reading developer attributes: %s", err.Error())
+		}
+		current = existing.Attributes
+	}
+
+	DeveloperData, err := setDeveloperData(d, meta, current)
 	if err != nil {
 		log.Printf("[ERROR] resourceDeveloperUpdate error in setDeveloperData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperUpdate error in setDeveloperData: %s", err.Error())
 	}
 
-	_, _, e := client.Developers.Update(DeveloperData)
+	e := updateDeveloper(client, oldEmail.(string), DeveloperData)
 	if e != nil {
 		log.Printf("[ERROR] resourceDeveloperUpdate error in developer update: %s", e.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperUpdate error in developer update: %s", e.Error())
@@ -137,10 +197,27 @@ func resourceDeveloperDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceDeveloperDelete START")
 
-	client := meta.(*apigee.EdgeClient)
-
-	_, err := client.Developers.Delete(d.Get("email").(string))
+	client, err := orgClient(d, meta)
 	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperDelete %s", err.Error())
+	}
+
+	if !d.Get("force_destroy").(bool) {
+		DeveloperData, _, err := client.Developers.Get(d.Get("email").(string))
+		if err != nil && !isNotFound(err) {
+			log.Printf("[ERROR] resourceDeveloperDelete error reading developer apps: %s", err.Error())
+			return fmt.Errorf("[ERROR] resourceDeveloperDelete error reading developer apps: %s", err.Error())
+		}
+		if err == nil {
+			d.Set("apps", flattenStringList(DeveloperData.Apps))
+			if err := checkForceDestroy(d, "developer", d.Get("email").(string), DeveloperData.Apps); err != nil {
+				return fmt.Errorf("[ERROR] resourceDeveloperDelete %s", err.Error())
+			}
+		}
+	}
+
+	_, err = client.Developers.Delete(d.Get("email").(string))
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceDeveloperDelete error in developer delete: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperDelete error in developer delete: %s", err.Error())
 	}
@@ -148,14 +225,24 @@ func resourceDeveloperDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func setDeveloperData(d *schema.ResourceData) (apigee.Developer, error) {
+// updateDeveloper replaces the developer known by email.  go-apigee-edge addresses the developer by its new email,
+// which does not exist yet when the email changes.
+func updateDeveloper(client *apigee.EdgeClient, email string, developer apigee.Developer) error {
+
+	req, err := client.NewRequest("PUT", path.Join("developers", email), developer, "")
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(req, nil)
+
+	return err
+}
+
+func setDeveloperData(d *schema.ResourceData, meta interface{}, current []apigee.Attribute) (apigee.Developer, error) {
 
 	log.Print("[DEBUG] setDeveloperData START")
 
-	attributes := []apigee.Attribute{}
-	if d.Get("attributes") != nil {
-		attributes = attributesFromMap(d.Get("attributes").(map[string]interface{}))
-	}
+	attributes := attributesWithDefaults(d, meta, current)
 
 	Developer := apigee.Developer{
 		Email:      d.Get("email").(string),
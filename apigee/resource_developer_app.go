@@ -4,10 +4,11 @@ import (
 	"fmt"
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	//"github.com/mitchellh/mapstructure"
 	"github.com/zambien/go-apigee-edge"
 	"log"
-	"strings"
+	"time"
 )
 
 func resourceDeveloperApp() *schema.Resource {
@@ -17,11 +18,24 @@ func resourceDeveloperApp() *schema.Resource {
 		Update: resourceDeveloperAppUpdate,
 		Delete: resourceDeveloperAppDelete,
 
+		CustomizeDiff: resourceDeveloperAppCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"developer_email": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			"name": {
 				Type:     schema.TypeString,
@@ -29,7 +43,7 @@ func resourceDeveloperApp() *schema.Resource {
 				ForceNew: true,
 			},
 			"api_products": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
@@ -41,6 +55,21 @@ func resourceDeveloperApp() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"attributes_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      attributesAuthoritative,
+				ValidateFunc: validation.StringInSlice([]string{attributesAuthoritative, attributesAdditive}, false),
+			},
+			"ignore_attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"default_attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
 			"credentials": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -71,7 +100,7 @@ func resourceDeveloperApp() *schema.Resource {
 				},
 			},
 			"scopes": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
@@ -107,18 +136,21 @@ func resourceDeveloperAppCreate(d *schema.ResourceData, meta interface{}) error
 
 	log.Print("[DEBUG] resourceDeveloperAppCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperAppCreate %s", err.Error())
+	}
 
 	u1, _ := uuid.NewV4()
 	d.SetId(u1.String())
 
-	DeveloperAppData, err := setDeveloperAppData(d)
+	DeveloperAppData, err := setDeveloperAppData(d, meta, nil)
 	if err != nil {
 		log.Printf("[ERROR] resourceDeveloperAppCreate error in setDeveloperAppData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperAppCreate error in setDeveloperAppData: %s", err.Error())
 	}
 
-	log.Printf("[DEBUG] resourceDeveloperAppCreate sending object: %+v\n", DeveloperAppData)
+	log.Printf("[DEBUG] resourceDeveloperAppCreate sending object: %s\n", redactedObject(DeveloperAppData))
 
 	_, _, e := client.DeveloperApps.Create(d.Get("developer_email").(string), DeveloperAppData)
 	if e != nil {
@@ -132,12 +164,16 @@ func resourceDeveloperAppCreate(d *schema.ResourceData, meta interface{}) error
 func resourceDeveloperAppRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceDeveloperAppRead START")
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperAppRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
-	DeveloperAppData, _, err := client.DeveloperApps.Get(d.Get("developer_email").(string), d.Get("name").(string))
+	DeveloperAppData, _, err := client.DeveloperApps.Get(developerAppOwner(d), d.Get("name").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceDeveloperAppRead error getting developer apps: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceDeveloperAppRead 404 encountered.  Removing state for developer app: %#v", d.Get("name").(string))
 			d.SetId("")
 			return nil
@@ -147,27 +183,21 @@ func resourceDeveloperAppRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	log.Printf("[DEBUG] resourceDeveloperAppRead DeveloperAppData: %+v\n", DeveloperAppData)
+	log.Printf("[DEBUG] resourceDeveloperAppRead DeveloperAppData: %s\n", redactedObject(DeveloperAppData))
 
 	//Scopes and apiProducts are tricky.  These actually result in an array which will always have
 	//one element unless an outside API is called.
 	//Get the most recent scopes from the last credentials set
 	scopes := flattenStringList(DeveloperAppData.Credentials[len(DeveloperAppData.Credentials)-1].Scopes)
 
-	//Apigee does not return products in the order you send them
 	//Get the most recent api products from the last credentials set
-	oldApiProducts := getStringList("api_products", d)
-	newApiProducts := apiProductsListFromCredentials(DeveloperAppData.Credentials[len(DeveloperAppData.Credentials)-1].ApiProducts)
-
-	if !arraySortedEqual(oldApiProducts, newApiProducts) {
-		d.Set("api_products", newApiProducts)
-	} else {
-		d.Set("api_products", oldApiProducts)
-	}
+	apiProducts := apiProductsListFromCredentials(DeveloperAppData.Credentials[len(DeveloperAppData.Credentials)-1].ApiProducts)
+	d.Set("api_products", apiProducts)
 
 
 	d.Set("name", DeveloperAppData.Name)
-	d.Set("attributes", DeveloperAppData.Attributes)
+	d.Set("attributes", flattenAttributes(DeveloperAppData.Attributes, d, meta))
+	d.Set("default_attributes", flattenDefaultAttributes(DeveloperAppData.Attributes, d, meta))
 	d.Set("scopes", scopes)
 	d.Set("callback_url", DeveloperAppData.CallbackUrl)
 	d.Set("app_id", DeveloperAppData.AppId)
@@ -198,15 +228,34 @@ func resourceDeveloperAppUpdate(d *schema.ResourceData, meta interface{}) error
 
 	log.Print("[DEBUG] resourceDeveloperAppUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperAppUpdate %s", err.Error())
+	}
+
+	var current []apigee.Attribute
+	if preservesAttributes(d) {
+		existing, _, err := client.DeveloperApps.Get(developerAppOwner(d), d.Get("name").(string))
+		if err != nil {
+			log.Printf("[ERROR] resourceDeveloperAppUpdate error reading developer app attributes: %s", err.Error())
+			return fmt.Errorf("[ERROR] resourceDeveloperAppUpdate error reading developer app attributes: %s", err.Error())
+		}
+		current = existing.Attributes
+	}
 
-	DeveloperAppData, err := setDeveloperAppData(d)
+	DeveloperAppData, err := setDeveloperAppData(d, meta, current)
 	if err != nil {
 		log.Printf("[ERROR] resourceDeveloperAppUpdate error in setDeveloperAppData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperAppUpdate error in setDeveloperAppData: %s", err.Error())
 	}
 
-	_, _, e := client.DeveloperApps.Update(d.Get("developer_email").(string), DeveloperAppData)
+	if d.HasChange("developer_email") {
+		if err := checkDeveloperAppOwner(client, d); err != nil {
+			return fmt.Errorf("[ERROR] resourceDeveloperAppUpdate %s", err.Error())
+		}
+	}
+
+	_, _, e := client.DeveloperApps.Update(developerAppOwner(d), DeveloperAppData)
 	if e != nil {
 		log.Printf("[ERROR] resourceDeveloperAppUpdate error in developer app update: %s", e.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperAppUpdate error in developer app update: %s", e.Error())
@@ -219,10 +268,13 @@ func resourceDeveloperAppDelete(d *schema.ResourceData, meta interface{}) error
 
 	log.Print("[DEBUG] resourceDeveloperAppDelete START")
 
-	client := meta.(*apigee.EdgeClient)
-
-	_, err := client.DeveloperApps.Delete(d.Get("developer_email").(string), d.Get("name").(string))
+	client, err := orgClient(d, meta)
 	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperAppDelete %s", err.Error())
+	}
+
+	_, err = client.DeveloperApps.Delete(developerAppOwner(d), d.Get("name").(string))
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceDeveloperAppDelete error in developer app delete: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceDeveloperAppDelete error in developer app delete: %s", err.Error())
 	}
@@ -230,7 +282,66 @@ func resourceDeveloperAppDelete(d *schema.ResourceData, meta interface{}) error
 	return nil
 }
 
-func setDeveloperAppData(d *schema.ResourceData) (apigee.DeveloperApp, error) {
+// developerAppOwner returns how the app's developer is addressed: by developer ID once it is known so that the app
+// follows the developer when their email changes, by email until then.
+func developerAppOwner(d interface{ Get(string) interface{} }) string {
+
+	if developerId := d.Get("developer_id").(string); developerId != "" {
+		return developerId
+	}
+
+	return d.Get("developer_email").(string)
+}
+
+// resourceDeveloperAppCustomizeDiff replaces the app when developer_email names another existing developer.  Apps
+// cannot move between developers, any other change of developer_email is the app's developer changing their email.
+// Default attributes are planned the way they are for developers and products.
+func resourceDeveloperAppCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+
+	if err := customizeDefaultAttributes(d, meta); err != nil {
+		return err
+	}
+
+	if d.Id() == "" || !d.HasChange("developer_email") || !d.NewValueKnown("developer_email") || d.Get("developer_id").(string) == "" {
+		return nil
+	}
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceDeveloperAppCustomizeDiff %s", err.Error())
+	}
+
+	developer, _, err := client.Developers.Get(d.Get("developer_email").(string))
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		log.Printf("[ERROR] resourceDeveloperAppCustomizeDiff error reading developer: %s", err.Error())
+		return fmt.Errorf("[ERROR] resourceDeveloperAppCustomizeDiff error reading developer: %s", err.Error())
+	}
+	if developer.DeveloperId != d.Get("developer_id").(string) {
+		return d.ForceNew("developer_email")
+	}
+
+	return nil
+}
+
+// checkDeveloperAppOwner makes sure the app's developer now has the new developer_email, the plan could not tell a
+// developer created in the same apply from the app's developer changing their email.
+func checkDeveloperAppOwner(client *apigee.EdgeClient, d *schema.ResourceData) error {
+
+	developer, _, err := client.Developers.Get(developerAppOwner(d))
+	if err != nil {
+		return fmt.Errorf("error reading developer of app %s: %s", d.Get("name").(string), err.Error())
+	}
+	if developer.Email != d.Get("developer_email").(string) {
+		return fmt.Errorf("app %s belongs to developer %s and cannot be moved to %s, replace it instead", d.Get("name").(string), developer.Email, d.Get("developer_email").(string))
+	}
+
+	return nil
+}
+
+func setDeveloperAppData(d *schema.ResourceData, meta interface{}, current []apigee.Attribute) (apigee.DeveloperApp, error) {
 
 	log.Print("[DEBUG] setDeveloperAppData START")
 
@@ -245,10 +356,7 @@ func setDeveloperAppData(d *schema.ResourceData) (apigee.DeveloperApp, error) {
 	}
 	log.Printf("[DEBUG] setDeveloperAppData scopes: %+v\n", scopes)
 
-	attributes := []apigee.Attribute{}
-	if d.Get("attributes") != nil {
-		attributes = attributesFromMap(d.Get("attributes").(map[string]interface{}))
-	}
+	attributes := attributesWithDefaults(d, meta, current)
 
 	DeveloperApp := apigee.DeveloperApp{
 		Name:         d.Get("name").(string),
@@ -7,7 +7,6 @@ import (
 	"github.com/zambien/go-apigee-edge"
 	"log"
 	"regexp"
-	"strings"
 	"testing"
 )
 
@@ -35,14 +34,14 @@ func TestAccDeveloperApp_Updated(t *testing.T) {
 						"apigee_developer_app.foo_developer_app", "name", "foo_developer_app_name_updated"),
 					resource.TestCheckResourceAttr(
 						"apigee_developer_app.foo_developer_app", "developer_email", "foo_developer_app_test_email@test.com"),
-					resource.TestCheckResourceAttr(
-						"apigee_developer_app.foo_developer_app", "api_products.0", "foo_product"),
-					resource.TestCheckResourceAttr(
-						"apigee_developer_app.foo_developer_app", "api_products.1", "bbb_product"),
-					resource.TestCheckResourceAttr(
-						"apigee_developer_app.foo_developer_app", "api_products.2", "aaa_product"),
-					resource.TestCheckResourceAttr(
-						"apigee_developer_app.foo_developer_app", "scopes.0", "READ"),
+					testCheckResourceSetAttr(
+						"apigee_developer_app.foo_developer_app", "api_products", "foo_product"),
+					testCheckResourceSetAttr(
+						"apigee_developer_app.foo_developer_app", "api_products", "bbb_product"),
+					testCheckResourceSetAttr(
+						"apigee_developer_app.foo_developer_app", "api_products", "aaa_product"),
+					testCheckResourceSetAttr(
+						"apigee_developer_app.foo_developer_app", "scopes", "READ"),
 					resource.TestCheckResourceAttr(
 						"apigee_developer_app.foo_developer_app", "callback_url", "https://www.google.com"),
 					//match integer
@@ -56,7 +55,7 @@ func TestAccDeveloperApp_Updated(t *testing.T) {
 
 func testAccCheckDeveloperAppDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := developerAppDestroyHelper(s, client); err != nil {
 		return err
@@ -66,7 +65,7 @@ func testAccCheckDeveloperAppDestroy(s *terraform.State) error {
 
 func testAccCheckDeveloperAppExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := developerAppExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckDeveloperAppExists: %s", err)
 			return err
@@ -142,7 +141,7 @@ func developerAppDestroyHelper(s *terraform.State, client *apigee.EdgeClient) er
 		_, _, err := client.DeveloperApps.Get("foo_developer_app_test_email@test.com", "foo_developer_app")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving developer app: %+v\n", err)
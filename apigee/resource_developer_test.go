@@ -6,7 +6,8 @@ import (
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/zambien/go-apigee-edge"
 	"log"
-	"strings"
+	"regexp"
+	"sort"
 	"testing"
 )
 
@@ -55,9 +56,160 @@ func TestAccDeveloper_Updated(t *testing.T) {
 	})
 }
 
+func TestDeveloperEmailChange(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testDeveloperEmailChangeConfig, "foo@example.com", "apigee_developer.foo.email"),
+			},
+			{
+				// The developer keeps their ID and apps, the app follows them.
+				Config: provider + fmt.Sprintf(testDeveloperEmailChangeConfig, "foo@example.org", "apigee_developer.foo.email"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_developer.foo", "developer_id", "developer-foo@example.com"),
+					resource.TestCheckResourceAttr("apigee_developer.foo", "apps.0", "foo-app"),
+					resource.TestCheckResourceAttr("apigee_developer_app.foo", "developer_email", "foo@example.org"),
+					resource.TestCheckResourceAttr("apigee_developer_app.foo", "consumer_key", "key-foo-app"),
+					testCheckFakeEntities(fake, "developers/foo@example.org", "developers/foo@example.org/apps/foo-app", "developers/bar@example.com"),
+				),
+			},
+			{
+				// Apps cannot move between developers, they are replaced.
+				Config: provider + fmt.Sprintf(testDeveloperEmailChangeConfig, "foo@example.org", "apigee_developer.bar.email"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_developer_app.foo", "developer_id", "developer-bar@example.com"),
+					testCheckFakeEntities(fake, "developers/foo@example.org", "developers/bar@example.com", "developers/bar@example.com/apps/foo-app"),
+				),
+			},
+		},
+	})
+}
+
+func TestDeveloperDeleteWithApps(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testDeveloperDeleteWithAppsConfig,
+			},
+			{
+				// An app created outside of Terraform keeps the developer from being destroyed.
+				PreConfig:   func() { fake.seed("developers/foo@example.com/apps/portal-app", `{"name": "portal-app"}`) },
+				Config:      provider,
+				ExpectError: regexp.MustCompile("developer foo@example.com still has apps portal-app"),
+			},
+			{
+				PreConfig: func() {
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					delete(fake.entities, "developers/foo@example.com/apps/portal-app")
+				},
+				Config: provider,
+				Check:  testCheckFakeEntities(fake),
+			},
+		},
+	})
+}
+
+func TestDeveloperForceDestroy(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testDeveloperForceDestroyConfig, "false"),
+			},
+			{
+				// An app created outside of Terraform keeps the developer from being destroyed.
+				PreConfig:   func() { fake.seed("developers/foo@example.com/apps/portal-app", `{"name": "portal-app"}`) },
+				Config:      provider,
+				ExpectError: regexp.MustCompile("developer foo@example.com still has apps portal-app"),
+			},
+			{
+				Config: provider + fmt.Sprintf(testDeveloperForceDestroyConfig, "true"),
+			},
+			{
+				Config: provider,
+				Check:  testCheckFakeEntities(fake),
+			},
+		},
+	})
+}
+
+// testCheckFakeEntities checks that the fake has exactly the entities at paths.
+func testCheckFakeEntities(fake *fakeEdge, paths ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		actual := []string{}
+		for path := range fake.entities {
+			actual = append(actual, path)
+		}
+		sort.Strings(actual)
+		sort.Strings(paths)
+		if fmt.Sprint(actual) != fmt.Sprint(paths) {
+			return fmt.Errorf("expected entities %v, got %v", paths, actual)
+		}
+		return nil
+	}
+}
+
+const testDeveloperEmailChangeConfig = `
+resource "apigee_developer" "foo" {
+   email      = "%s"
+   first_name = "Foo"
+   last_name  = "Bar"
+   user_name  = "foo"
+}
+
+resource "apigee_developer" "bar" {
+   email      = "bar@example.com"
+   first_name = "Bar"
+   last_name  = "Baz"
+   user_name  = "bar"
+}
+
+resource "apigee_developer_app" "foo" {
+   developer_email = %s
+   name            = "foo-app"
+}
+`
+
+const testDeveloperDeleteWithAppsConfig = `
+resource "apigee_developer" "foo" {
+   email      = "foo@example.com"
+   first_name = "Foo"
+   last_name  = "Bar"
+   user_name  = "foo"
+}
+`
+
+const testDeveloperForceDestroyConfig = `
+resource "apigee_developer" "foo" {
+   email         = "foo@example.com"
+   first_name    = "Foo"
+   last_name     = "Bar"
+   user_name     = "foo"
+   force_destroy = %s
+}
+`
+
 func testAccCheckDeveloperDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := developerDestroyHelper(s, client); err != nil {
 		return err
@@ -67,7 +219,7 @@ func testAccCheckDeveloperDestroy(s *terraform.State) error {
 
 func testAccCheckDeveloperExists(n string, email string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := developerExistsHelper(s, client, email); err != nil {
 			log.Printf("Error in testAccCheckDeveloperExists: %s", err)
 			return err
@@ -111,7 +263,7 @@ func developerDestroyHelper(s *terraform.State, client *apigee.EdgeClient) error
 		_, _, err := client.Developers.Get("foo_developer")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving developer  %+v\n", err)
@@ -3,10 +3,11 @@ package apigee
 import (
 	"fmt"
 	"log"
-	"strings"
+	"strconv"
+	"time"
 
-	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/zambien/go-apigee-edge"
 )
 
@@ -19,66 +20,174 @@ func resourceProduct() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: resourceProductImport,
 		},
+		CustomizeDiff: resourceProductCustomizeDiff,
+
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceProductV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceProductStateUpgradeV0,
+			},
+			{
+				Version: 1,
+				Type:    resourceProductV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceProductStateUpgradeV1,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
 				ForceNew: true,
 			},
+			//deletion_protection makes destroying the product fail until it is set to false by an apply.
+			"deletion_protection": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"display_name": {
 				Type:     schema.TypeString,
 				Optional: true,
 				Computed: true,
 			},
 			"approval_type": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"auto", "manual"}, false),
 			},
 			"attributes": {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"attributes_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      attributesAuthoritative,
+				ValidateFunc: validation.StringInSlice([]string{attributesAuthoritative, attributesAdditive}, false),
+			},
+			"ignore_attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"default_attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
 			"description": {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
 			"api_resources": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
+				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 			"proxies": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
+				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 			"quota": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"quota_interval": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"quota_time_unit": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(quotaTimeUnits, false),
 			},
 			"scopes": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 			"environments": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"validate_references": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"validate_deployments": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"operation_config_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      operationConfigTypeProxy,
+				ValidateFunc: validation.StringInSlice([]string{operationConfigTypeProxy, operationConfigTypeRemoteService}, false),
+			},
+			"operation": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"api_resources", "proxies"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"api_source": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"resources": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"methods": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"quota": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"quota_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"quota_time_unit": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice(quotaTimeUnits, false),
+						},
+						"attributes": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -87,18 +196,21 @@ func resourceProductCreate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceProductCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceProductCreate %s", err.Error())
+	}
 
-	u1, _ := uuid.NewV4()
-	d.SetId(u1.String())
+	//The ID is the import ID so that created and imported products are alike.
+	d.SetId(d.Get("name").(string))
 
-	ProductData, err := setProductData(d)
+	ProductData, err := setProductData(d, meta, nil)
 	if err != nil {
 		log.Printf("[ERROR] resourceProductCreate error in setProductData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceProductCreate error in setProductData: %s", err.Error())
 	}
 
-	_, _, e := client.Products.Create(ProductData)
+	e := createOrUpdateProduct(client, "POST", product{Product: ProductData, OperationGroup: expandProductOperationGroup(d)})
 	if e != nil {
 		log.Printf("[ERROR] resourceProductCreate error in product creation: %s", e.Error())
 		return fmt.Errorf("[ERROR] resourceProductCreate error in product creation: %s", e.Error())
@@ -110,32 +222,17 @@ func resourceProductCreate(d *schema.ResourceData, meta interface{}) error {
 func resourceProductImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	log.Print("[DEBUG] resourceProductImport START")
 
-	client := meta.(*apigee.EdgeClient)
-	productData, _, err := client.Products.Get(d.Id())
-	if err != nil {
-		return []*schema.ResourceData{}, fmt.Errorf("[DEBUG] resourceProductImport. Error getting product: %v", err)
-	}
+	importOrg(d)
+
 	d.Set("name", d.Id())
-	apiResources := flattenStringList(productData.ApiResources)
-	proxies := flattenStringList(productData.Proxies)
-	scopes := flattenStringList(productData.Scopes)
-	environments := flattenStringList(productData.Environments)
-	if productData.DisplayName == "" {
-		d.Set("display_name", productData.Name)
-	} else {
-		d.Set("display_name", productData.DisplayName)
+	setImportDefaults(d, resourceProduct())
+
+	if err := resourceProductRead(d, meta); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceProductImport error reading product: %s", err.Error())
+	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceProductImport product %s does not exist", d.Get("name").(string))
 	}
-	d.Set("display_name", productData.DisplayName)
-	d.Set("description", productData.Description)
-	d.Set("approval_type", productData.ApprovalType)
-	d.Set("attributes", productData.Attributes)
-	d.Set("apiResource", apiResources)
-	d.Set("proxies", proxies)
-	d.Set("quota", productData.Quota)
-	d.Set("quota_interval", productData.QuotaInterval)
-	d.Set("quota_time_unit", productData.QuotaTimeUnit)
-	d.Set("environments", environments)
-	d.Set("scopes", scopes)
 
 	return []*schema.ResourceData{d}, nil
 }
@@ -143,12 +240,16 @@ func resourceProductImport(d *schema.ResourceData, meta interface{}) ([]*schema.
 func resourceProductRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceProductRead START")
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceProductRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
-	ProductData, _, err := client.Products.Get(d.Get("name").(string))
+	ProductData, err := getProduct(client, d.Get("name").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceProductRead error getting products: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceProductRead 404 encountered.  Removing state for product: %#v", d.Get("name").(string))
 			d.SetId("")
 			return nil
@@ -165,18 +266,23 @@ func resourceProductRead(d *schema.ResourceData, meta interface{}) error {
 	} else {
 		d.Set("display_name", ProductData.DisplayName)
 	}
-	d.Set("display_name", ProductData.DisplayName)
 	d.Set("description", ProductData.Description)
 	d.Set("approval_type", ProductData.ApprovalType)
-	d.Set("attributes", ProductData.Attributes)
-	d.Set("quota", ProductData.Quota)
-	d.Set("quota_interval", ProductData.QuotaInterval)
+	d.Set("attributes", flattenAttributes(ProductData.Attributes, d, meta))
+	d.Set("default_attributes", flattenDefaultAttributes(ProductData.Attributes, d, meta))
+	d.Set("quota", quotaInt(ProductData.Quota))
+	d.Set("quota_interval", quotaInt(ProductData.QuotaInterval))
 	d.Set("quota_time_unit", ProductData.QuotaTimeUnit)
 
-	updateResourceOnSortedArrayChange(d, "apiResource", ProductData.ApiResources)
-	updateResourceOnSortedArrayChange(d, "proxies", ProductData.Proxies)
-	updateResourceOnSortedArrayChange(d, "environments", ProductData.Environments)
-	updateResourceOnSortedArrayChange(d, "scopes", ProductData.Scopes)
+	d.Set("api_resources", ProductData.ApiResources)
+	d.Set("proxies", ProductData.Proxies)
+	d.Set("environments", ProductData.Environments)
+	d.Set("scopes", ProductData.Scopes)
+
+	d.Set("operation", flattenProductOperationGroup(ProductData.OperationGroup))
+	if ProductData.OperationGroup != nil && ProductData.OperationGroup.OperationConfigType != "" {
+		d.Set("operation_config_type", ProductData.OperationGroup.OperationConfigType)
+	}
 
 	return nil
 }
@@ -185,15 +291,32 @@ func resourceProductUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceProductUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceProductUpdate %s", err.Error())
+	}
+
+	if onlyDeletionProtectionChanged(d, resourceProduct()) {
+		return resourceProductRead(d, meta)
+	}
+
+	var current []apigee.Attribute
+	if preservesAttributes(d) {
+		existing, err := getProduct(client, d.Get("name").(string))
+		if err != nil {
+			log.Printf("[ERROR] resourceProductUpdate error reading product attributes: %s", err.Error())
+			return fmt.Errorf("[ERROR] resourceProductUpdate error reading product attributes: %s", err.Error())
+		}
+		current = existing.Attributes
+	}
 
-	ProductData, err := setProductData(d)
+	ProductData, err := setProductData(d, meta, current)
 	if err != nil {
 		log.Printf("[ERROR] resourceProductUpdate error in setProductData: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceProductUpdate error in setProductData: %s", err.Error())
 	}
 
-	_, _, e := client.Products.Update(ProductData)
+	e := createOrUpdateProduct(client, "PUT", product{Product: ProductData, OperationGroup: expandProductOperationGroup(d)})
 	if e != nil {
 		log.Printf("[ERROR] resourceProductUpdate error in product update: %s", e.Error())
 		return fmt.Errorf("[ERROR] resourceProductUpdate error in product update: %s", e.Error())
@@ -202,14 +325,45 @@ func resourceProductUpdate(d *schema.ResourceData, meta interface{}) error {
 	return resourceProductRead(d, meta)
 }
 
+// resourceProductCustomizeDiff fails the plan when a quota is given without its interval and time unit, either of the
+// product or of one of its operations, and with validate_references when the product names proxies or environments
+// that do not exist.  It also adds the provider's default attributes to the plan.
+func resourceProductCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+
+	if err := customizeDefaultAttributes(d, meta); err != nil {
+		return err
+	}
+	if err := validateQuota(d, ""); err != nil {
+		return err
+	}
+	for i := range d.Get("operation").([]interface{}) {
+		if err := validateQuota(d, fmt.Sprintf("operation.%d.", i)); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("validate_references").(bool) {
+		return validateProductReferences(d, meta)
+	}
+
+	return nil
+}
+
 func resourceProductDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceProductDelete START")
 
-	client := meta.(*apigee.EdgeClient)
+	if err := checkDeletionProtection(d, fmt.Sprintf("product %s", d.Get("name").(string))); err != nil {
+		return fmt.Errorf("[ERROR] resourceProductDelete %s", err.Error())
+	}
 
-	_, err := client.Products.Delete(d.Get("name").(string))
+	client, err := orgClient(d, meta)
 	if err != nil {
+		return fmt.Errorf("[ERROR] resourceProductDelete %s", err.Error())
+	}
+
+	_, err = client.Products.Delete(d.Get("name").(string))
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceProductDelete error in product delete: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceProductDelete error in product delete: %s", err.Error())
 	}
@@ -217,7 +371,7 @@ func resourceProductDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func setProductData(d *schema.ResourceData) (apigee.Product, error) {
+func setProductData(d *schema.ResourceData, meta interface{}, current []apigee.Attribute) (apigee.Product, error) {
 
 	log.Print("[DEBUG] setProductData START")
 
@@ -240,10 +394,7 @@ func setProductData(d *schema.ResourceData) (apigee.Product, error) {
 		scopes = getStringList("scopes", d)
 	}
 
-	attributes := []apigee.Attribute{}
-	if d.Get("attributes") != nil {
-		attributes = attributesFromMap(d.Get("attributes").(map[string]interface{}))
-	}
+	attributes := attributesWithDefaults(d, meta, current)
 
 	environments := []string{""}
 	if d.Get("environments") != nil {
@@ -258,8 +409,8 @@ func setProductData(d *schema.ResourceData) (apigee.Product, error) {
 		Description:   d.Get("description").(string),
 		ApiResources:  apiResources,
 		Proxies:       proxies,
-		Quota:         d.Get("quota").(string),
-		QuotaInterval: d.Get("quota_interval").(string),
+		Quota:         quotaString(d.Get("quota").(int)),
+		QuotaInterval: quotaString(d.Get("quota_interval").(int)),
 		QuotaTimeUnit: d.Get("quota_time_unit").(string),
 		Scopes:        scopes,
 		Environments:  environments,
@@ -267,3 +418,39 @@ func setProductData(d *schema.ResourceData) (apigee.Product, error) {
 
 	return Product, nil
 }
+
+// quotaTimeUnits are the units of a quota interval.
+var quotaTimeUnits = []string{"minute", "hour", "day", "month"}
+
+// validateQuota checks that quota, quota_interval and quota_time_unit below prefix are set together or not at all.
+func validateQuota(d *schema.ResourceDiff, prefix string) error {
+
+	set := 0
+	for _, key := range []string{"quota", "quota_interval", "quota_time_unit"} {
+		if !d.NewValueKnown(prefix + key) {
+			return nil
+		}
+		if _, ok := d.GetOk(prefix + key); ok {
+			set++
+		}
+	}
+	if set != 0 && set != 3 {
+		return fmt.Errorf("[ERROR] %squota, %squota_interval and %squota_time_unit must be set together", prefix, prefix, prefix)
+	}
+
+	return nil
+}
+
+// quotaString returns a quota number as Apigee sends it, a string that is empty when there is no quota.
+func quotaString(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+// quotaInt returns a quota number Apigee sent as a string, 0 when there is none.
+func quotaInt(v string) int {
+	i, _ := strconv.Atoi(v)
+	return i
+}
@@ -0,0 +1,123 @@
+package apigee
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceProductV0 is the schema of apigee_product before quota and quota_interval became numbers.
+func resourceProductV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"display_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"approval_type": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"attributes": {
+				Type:     schema.TypeMap,
+				Optional: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"api_resources": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"proxies": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"quota": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"quota_interval": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"quota_time_unit": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"scopes": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"environments": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// resourceProductStateUpgradeV0 turns the quota and quota_interval strings into numbers, empty strings are dropped.
+func resourceProductStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceProductStateUpgradeV0 START")
+
+	for _, key := range []string{"quota", "quota_interval"} {
+		v, ok := rawState[key].(string)
+		if !ok {
+			continue
+		}
+		if v == "" {
+			delete(rawState, key)
+			continue
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			log.Printf("[ERROR] resourceProductStateUpgradeV0 %s is not a number: %#v", key, v)
+			return nil, fmt.Errorf("[ERROR] resourceProductStateUpgradeV0 %s is not a number: %#v", key, v)
+		}
+		rawState[key] = i
+	}
+
+	return rawState, nil
+}
+
+// resourceProductV1 is the schema of apigee_product before its lists became sets.
+func resourceProductV1() *schema.Resource {
+
+	r := resourceProductV0()
+	r.Schema["quota"] = &schema.Schema{Type: schema.TypeInt, Optional: true}
+	r.Schema["quota_interval"] = &schema.Schema{Type: schema.TypeInt, Optional: true}
+
+	return r
+}
+
+// resourceProductStateUpgradeV1 moves the ID from the UUID the product was created with to its name, which is how
+// products are read and imported.  The lists that became sets need no change, both are arrays in the state.
+func resourceProductStateUpgradeV1(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceProductStateUpgradeV1 START")
+
+	if name, ok := rawState["name"].(string); ok && name != "" {
+		rawState["id"] = name
+	}
+
+	return rawState, nil
+}
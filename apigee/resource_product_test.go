@@ -1,9 +1,11 @@
 package apigee
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
-	"strings"
+	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -42,16 +44,16 @@ func TestAccProduct_Updated(t *testing.T) {
 						"apigee_product.foo_product", "description", "no one ever fills this out"),
 					resource.TestCheckResourceAttr(
 						"apigee_product.foo_product", "approval_type", "auto"),
-					resource.TestCheckResourceAttr(
-						"apigee_product.foo_product", "api_resources.0", "/**"),
-					resource.TestCheckResourceAttr(
-						"apigee_product.foo_product", "proxies.0", "tf_helloworld"),
+					testCheckResourceSetAttr(
+						"apigee_product.foo_product", "api_resources", "/**"),
+					testCheckResourceSetAttr(
+						"apigee_product.foo_product", "proxies", "tf_helloworld"),
 					resource.TestCheckResourceAttr(
 						"apigee_product.foo_product", "quota", "1000"),
 					resource.TestCheckResourceAttr(
 						"apigee_product.foo_product", "quota_interval", "2"),
-					resource.TestCheckResourceAttr(
-						"apigee_product.foo_product", "scopes.0", "READ"),
+					testCheckResourceSetAttr(
+						"apigee_product.foo_product", "scopes", "READ"),
 					resource.TestCheckResourceAttr(
 						"apigee_product.foo_product", "quota_time_unit", "minute"),
 					resource.TestCheckResourceAttr(
@@ -60,8 +62,8 @@ func TestAccProduct_Updated(t *testing.T) {
 						"apigee_product.foo_product", "attributes.custom1", "customval1"),
 					resource.TestCheckResourceAttr(
 						"apigee_product.foo_product", "attributes.custom2", "customval2"),
-					resource.TestCheckResourceAttr(
-						"apigee_product.foo_product", "environments.0", "test"),
+					testCheckResourceSetAttr(
+						"apigee_product.foo_product", "environments", "test"),
 				),
 			},
 		},
@@ -70,7 +72,7 @@ func TestAccProduct_Updated(t *testing.T) {
 
 func testAccCheckProductDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := productDestroyHelper(s, client); err != nil {
 		return err
@@ -80,7 +82,7 @@ func testAccCheckProductDestroy(s *terraform.State) error {
 
 func testAccCheckProductExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := productExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckProductExists: %s", err)
 			return err
@@ -158,7 +160,7 @@ func productDestroyHelper(s *terraform.State, client *apigee.EdgeClient) error {
 		_, _, err := client.Products.Get("foo_product")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving product  %+v\n", err)
@@ -186,3 +188,289 @@ func productExistsHelper(s *terraform.State, client *apigee.EdgeClient, name str
 	}
 	return nil
 }
+
+func TestProductOperations(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testProductOperationsConfig, 50),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_product.orders", "operation.#", "2"),
+					resource.TestCheckResourceAttr("apigee_product.orders", "operation.0.api_source", "orders"),
+					resource.TestCheckResourceAttr("apigee_product.orders", "operation.0.quota", "1000"),
+					resource.TestCheckResourceAttr("apigee_product.orders", "operation.1.methods.0", "POST"),
+					resource.TestCheckResourceAttr("apigee_product.orders", "operation.1.quota", "50"),
+					resource.TestCheckResourceAttr("apigee_product.orders", "operation.1.attributes.tier", "write"),
+					testCheckFakeOperationGroup(fake, `{"operationConfigs":[`+
+						`{"apiSource":"orders","operations":[{"resource":"/orders","methods":["GET"]},{"resource":"/orders/*","methods":["GET"]}],"quota":{"limit":"1000","interval":"1","timeUnit":"minute"}},`+
+						`{"apiSource":"orders","operations":[{"resource":"/orders","methods":["POST"]}],"quota":{"limit":"50","interval":"1","timeUnit":"minute"},"attributes":[{"name":"tier","value":"write"}]}],`+
+						`"operationConfigType":"proxy"}`),
+				),
+			},
+			{
+				Config: provider + fmt.Sprintf(testProductOperationsConfig, 100),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_product.orders", "operation.1.quota", "100"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckFakeOperationGroup(fake *fakeEdge, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		actual, err := json.Marshal(fake.entities["apiproducts/orders"]["operationGroup"])
+		if err != nil {
+			return err
+		}
+		var expectedGroup, actualGroup productOperationGroup
+		json.Unmarshal([]byte(expected), &expectedGroup)
+		json.Unmarshal(actual, &actualGroup)
+		if !reflect.DeepEqual(expectedGroup, actualGroup) {
+			return fmt.Errorf("expected operation group %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+const testProductOperationsConfig = `
+resource "apigee_product" "orders" {
+   name          = "orders"
+   approval_type = "auto"
+
+   operation {
+      api_source      = "orders"
+      resources       = ["/orders", "/orders/*"]
+      methods         = ["GET"]
+      quota           = 1000
+      quota_interval  = 1
+      quota_time_unit = "minute"
+   }
+
+   operation {
+      api_source      = "orders"
+      resources       = ["/orders"]
+      methods         = ["POST"]
+      quota           = %d
+      quota_interval  = 1
+      quota_time_unit = "minute"
+      attributes = {
+         tier = "write"
+      }
+   }
+}
+`
+
+func TestProductValidation(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	for _, test := range []struct {
+		fields   string
+		expected string
+	}{
+		{`approval_type = "automatic"`, `expected approval_type to be one of \[auto manual\]`},
+		{`approval_type = "auto"
+   quota = 1000
+   quota_interval = 1
+   quota_time_unit = "minutes"`, `expected quota_time_unit to be one of`},
+		{`approval_type = "auto"
+   quota = "lots"`, `quota`},
+		{`approval_type = "auto"
+   quota = 1000`, `quota, quota_interval and quota_time_unit must be set together`},
+		{`approval_type = "auto"
+   operation {
+      api_source = "orders"
+      resources = ["/orders"]
+      quota = 50
+   }`, `operation.0.quota, operation.0.quota_interval and operation.0.quota_time_unit must be set together`},
+	} {
+		resource.UnitTest(t, resource.TestCase{
+			Providers: testAccProviders,
+			Steps: []resource.TestStep{
+				{
+					Config:      provider + fmt.Sprintf(testProductValidationConfig, test.fields),
+					ExpectError: regexp.MustCompile(test.expected),
+				},
+			},
+		})
+	}
+}
+
+const testProductValidationConfig = `
+resource "apigee_product" "foo" {
+   name = "foo"
+   %s
+}
+`
+
+func TestResourceProductStateUpgradeV0(t *testing.T) {
+
+	actual, err := resourceProductStateUpgradeV0(map[string]interface{}{
+		"name":            "foo",
+		"quota":           "1000",
+		"quota_interval":  "",
+		"quota_time_unit": "minute",
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]interface{}{"name": "foo", "quota": 1000, "quota_time_unit": "minute"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+
+	if _, err := resourceProductStateUpgradeV0(map[string]interface{}{"quota": "lots"}, nil); err == nil {
+		t.Fatal("expected a quota that is not a number to fail the upgrade")
+	}
+}
+
+func TestResourceProductStateUpgradeV1(t *testing.T) {
+
+	actual, err := resourceProductStateUpgradeV1(map[string]interface{}{
+		"id":      "0b9a8f1e-5c2d-4e7a-9f3b-2d6c1a4e8b70",
+		"name":    "foo",
+		"proxies": []interface{}{"helloworld"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]interface{}{"id": "foo", "name": "foo", "proxies": []interface{}{"helloworld"}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestProductValidateReferences(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	fake.seed("environments/test", `{"name":"test"}`)
+	fake.seed("environments/prod", `{"name":"prod"}`)
+	fake.bundles["apis/orders"] = [][]byte{[]byte("bundle")}
+	fake.deployments["apis/orders"] = map[string][]int{"test": {1}}
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      provider + fmt.Sprintf(testProductValidateReferencesConfig, `"orders", "payments"`, `"test", "staging"`, false),
+				ExpectError: regexp.MustCompile(`environment staging does not exist, proxy payments does not exist`),
+			},
+			{
+				Config:      provider + fmt.Sprintf(testProductValidateReferencesConfig, `"orders"`, `"test", "prod"`, true),
+				ExpectError: regexp.MustCompile(`proxy orders is not deployed to prod`),
+			},
+			{
+				Config: provider + fmt.Sprintf(testProductValidateReferencesConfig, `"orders"`, `"test"`, true),
+				Check:  resource.TestCheckResourceAttr("apigee_product.foo", "validate_references", "true"),
+			},
+		},
+	})
+}
+
+const testProductValidateReferencesConfig = `
+resource "apigee_product" "foo" {
+   name                 = "foo"
+   approval_type        = "auto"
+   proxies              = [%s]
+   environments         = [%s]
+   validate_references  = true
+   validate_deployments = %t
+}
+`
+
+func TestProductSetsIgnoreOrder(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testProductSetsConfig
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_product.foo", "proxies.#", "2"),
+					testCheckResourceSetAttr("apigee_product.foo", "proxies", "payments"),
+					testCheckResourceSetAttr("apigee_product.foo", "api_resources", "/orders/**"),
+				),
+			},
+			{
+				// The plan after apply must be empty, Apigee returning the lists in another order causes no diff.
+				PreConfig: func() {
+					fake.edit("apiproducts/foo", func(entity map[string]interface{}) {
+						for _, key := range []string{"apiResources", "proxies", "scopes", "environments"} {
+							list := entity[key].([]interface{})
+							for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+								list[i], list[j] = list[j], list[i]
+							}
+						}
+					})
+				},
+				Config: config,
+			},
+		},
+	})
+}
+
+const testProductSetsConfig = `
+resource "apigee_product" "foo" {
+   name          = "foo"
+   approval_type = "auto"
+   api_resources = ["/orders/**", "/payments/**"]
+   proxies       = ["payments", "orders"]
+   scopes        = ["WRITE", "READ"]
+   environments  = ["test", "prod"]
+}
+`
+
+func TestProductImport(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testProductImportConfig
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:            config,
+				ResourceName:      "apigee_product.foo",
+				ImportState:       true,
+				ImportStateId:     "foo",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testProductImportConfig = `
+resource "apigee_product" "foo" {
+   name            = "foo"
+   display_name    = "Foo"
+   description     = "the foo product"
+   approval_type   = "manual"
+   api_resources   = ["/**", "/foo"]
+   proxies         = ["foo"]
+   quota           = 1000
+   quota_interval  = 1
+   quota_time_unit = "minute"
+   scopes          = ["READ"]
+   environments    = ["test", "prod"]
+   attributes = {
+      access = "public"
+   }
+}
+`
@@ -1,15 +1,12 @@
 package apigee
 
 import (
-	"strings"
-
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/gofrs/uuid"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/zambien/go-apigee-edge"
 )
 
 func resourceSharedFlow() *schema.Resource {
@@ -22,12 +19,39 @@ func resourceSharedFlow() *schema.Resource {
 			State: resourceSharedFlowImport,
 		},
 
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceSharedFlowV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceSharedFlowStateUpgradeV0,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
 				ForceNew: true,
 			},
+			//deletion_protection makes destroying the shared flow fail until it is set to false by an apply.
+			"deletion_protection": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"bundle": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -52,9 +76,10 @@ func resourceSharedFlow() *schema.Resource {
 func resourceSharedFlowCreate(d *schema.ResourceData, meta interface{}) error {
 	log.Print("[DEBUG] resourceSharedFlowCreate START")
 
-	client := meta.(*apigee.EdgeClient)
-
-	u1, _ := uuid.NewV4()
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowCreate %s", err.Error())
+	}
 
 	sharedFlowRev, _, err := client.SharedFlows.Import(d.Get("name").(string), d.Get("bundle").(string))
 
@@ -63,7 +88,8 @@ func resourceSharedFlowCreate(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("[ERROR] resourceSharedFlowCreate error importing shared_flow: %s", err.Error())
 	}
 
-	d.SetId(u1.String())
+	//The ID is the import ID so that created and imported shared flows are alike.
+	d.SetId(d.Get("name").(string))
 	d.Set("name", d.Get("name").(string))
 	d.Set("revision", sharedFlowRev.Revision.String())
 	d.Set("revision_sha", d.Get("bundle_sha").(string))
@@ -74,27 +100,34 @@ func resourceSharedFlowCreate(d *schema.ResourceData, meta interface{}) error {
 func resourceSharedFlowImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	log.Print("[DEBUG] resourceSharedFlowImport START")
 
-	client := meta.(*apigee.EdgeClient)
-	sharedFlow, _, err := client.SharedFlows.Get(d.Id())
-	if err != nil {
-		return []*schema.ResourceData{}, fmt.Errorf("[DEBUG] resourceSharedFlowImport. Error getting deployment shared flow: %v", err)
-	}
-	latestRev := sharedFlow.Revisions[len(sharedFlow.Revisions)-1]
+	importOrg(d)
 
-	d.Set("revision", latestRev)
 	d.Set("name", d.Id())
+	setImportDefaults(d, resourceSharedFlow())
+
+	if err := resourceSharedFlowRead(d, meta); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceSharedFlowImport error reading shared flow: %s", err.Error())
+	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceSharedFlowImport shared flow %s does not exist", d.Get("name").(string))
+	}
+
 	return []*schema.ResourceData{d}, nil
 }
 
 func resourceSharedFlowRead(d *schema.ResourceData, meta interface{}) error {
 	log.Print("[DEBUG] resourceSharedFlowRead START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	u, _, err := client.SharedFlows.Get(d.Get("name").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceSharedFlowRead error reading shared flows: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceSharedFlowRead 404 encountered.  Removing state for shared flow: %#v", d.Get("name").(string))
 			d.SetId("")
 			return nil
@@ -108,7 +141,7 @@ func resourceSharedFlowRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] resourceSharedFlowRead.  revision_sha before: %#v", d.Get("revision_sha").(string))
 	d.Set("revision_sha", d.Get("bundle_sha").(string))
 	log.Printf("[DEBUG] resourceSharedFlowRead.  revision_sha after: %#v", d.Get("revision_sha").(string))
-	d.Set("revision", latestRev)
+	d.Set("revision", latestRev.String())
 	d.Set("name", u.Name)
 
 	return nil
@@ -118,7 +151,14 @@ func resourceSharedFlowUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceSharedFlowUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowUpdate %s", err.Error())
+	}
+
+	if onlyDeletionProtectionChanged(d, resourceSharedFlow()) {
+		return resourceSharedFlowRead(d, meta)
+	}
 
 	if d.HasChange("name") {
 		log.Printf("[INFO] resourceSharedFlowUpdate name changed to: %#v\n", d.Get("name"))
@@ -144,25 +184,34 @@ func resourceSharedFlowDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceSharedFlowDelete START")
 
-	client := meta.(*apigee.EdgeClient)
+	if err := checkDeletionProtection(d, fmt.Sprintf("shared flow %s", d.Get("name").(string))); err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowDelete %s", err.Error())
+	}
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowDelete %s", err.Error())
+	}
 
 	//We have to handle retries in a special way here since this is a DELETE.  Note this used to work fine without retries.
-	deleted := false
-	tries := 0
-	for !deleted && tries < 3 {
+	err = resource.Retry(d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
 		_, _, err := client.SharedFlows.Delete(d.Get("name").(string))
 		if err != nil {
+			if isNotFound(err) {
+				return nil
+			}
 			//This is a race condition with Apigee APIs.  Wait and try again.
-			if strings.Contains(err.Error(), "Undeploy the shared flow and try again") {
+			if isConflict(err) || isRateLimited(err) {
 				log.Printf("[ERROR] resourceSharedFlowDelete shared flow still exists.  We will wait and try again.")
-				time.Sleep(5 * time.Second)
-			} else {
-				log.Printf("[ERROR] resourceSharedFlowDelete error deleting shared flow: %s", err.Error())
-				return fmt.Errorf("[ERROR] resourceSharedFlowDelete error deleting api_proxshared flowy: %s", err.Error())
+				return resource.RetryableError(err)
 			}
+			return resource.NonRetryableError(err)
 		}
-		deleted = true
-		tries += tries
+		return nil
+	})
+	if err != nil {
+		log.Printf("[ERROR] resourceSharedFlowDelete error deleting shared flow: %s", err.Error())
+		return fmt.Errorf("[ERROR] unable to delete shared flow: %s", err.Error())
 	}
 
 	return nil
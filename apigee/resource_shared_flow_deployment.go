@@ -5,9 +5,10 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/zambien/go-apigee-edge"
 )
 
@@ -20,6 +21,27 @@ func resourceSharedFlowDeployment() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: resourceSharedFlowDeploymentImport,
 		},
+		CustomizeDiff: deploymentCustomizeDiff,
+
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceSharedFlowDeploymentV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceSharedFlowDeploymentStateUpgradeV0,
+			},
+			{
+				Version: 1,
+				Type:    resourceSharedFlowDeploymentV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceSharedFlowDeploymentStateUpgradeV1,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"shared_flow_name": {
@@ -29,7 +51,8 @@ func resourceSharedFlowDeployment() *schema.Resource {
 			},
 			"org": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				Computed: true,
 				ForceNew: true,
 			},
 			"env": {
@@ -41,12 +64,40 @@ func resourceSharedFlowDeployment() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			//deletion_protection makes destroying the deployment fail until it is set to false by an apply.
+			"deletion_protection": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"delay": {
 				Type:     schema.TypeInt,
 				Optional: true,
 				Default:  0,
 			},
+			//override = true picks strategy seamless.  false is the default, it cannot be told from override not being
+			//set and leaves the choice to strategy.
 			"override": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"strategy"},
+				Deprecated:    "use strategy, override = true is strategy seamless",
+			},
+			//strategy is how a revision replaces the one deployed, the same on create and update.  delay applies to
+			//seamless deployments only.
+			"strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      deploymentStrategySeamless,
+				ValidateFunc: validation.StringInSlice(deploymentStrategies, false),
+			},
+			//previous_revision is the revision that was deployed to the environment before the last deployment.
+			"previous_revision": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"wait_for_ready": {
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
@@ -57,24 +108,26 @@ func resourceSharedFlowDeployment() *schema.Resource {
 
 func resourceSharedFlowDeploymentImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	log.Print("[DEBUG] resourceSharedFlowDeploymentImport START")
-	client := meta.(*apigee.EdgeClient)
 
-	splits := strings.Split(d.Id(), "_")
-	if len(splits) < 2 {
+	importOrg(d)
+
+	splits := strings.Split(strings.TrimSuffix(d.Id(), "_deployment"), "_")
+	if len(splits) < 2 || !strings.HasSuffix(d.Id(), "_deployment") {
 		return []*schema.ResourceData{}, fmt.Errorf("[ERR] Wrong format of resource: %s. Please follow '{name}_{env}_deployment'", d.Id())
 	}
-	nameOffset := len(splits[len(splits)-1]) + len(splits[len(splits)-2])
-	envOffset := len(splits[len(splits)-1])
-	name := d.Id()[:(len(d.Id())-nameOffset)-2]
-	IDEnv := d.Id()[len(name)+1 : (len(d.Id())-envOffset)-1]
-	deployment, _, err := client.SharedFlows.GetDeployments(name)
-	if err != nil {
-		log.Printf("[DEBUG] resourceSharedFlowDeploymentImport. Error getting deployment: %v", err)
-		return nil, nil
-	}
-	d.Set("org", deployment.Organization)
-	d.Set("shared_flow_name", deployment.Name)
+	IDEnv := splits[len(splits)-1]
+	name := strings.TrimSuffix(d.Id(), "_"+IDEnv+"_deployment")
+
+	d.Set("shared_flow_name", name)
 	d.Set("env", IDEnv)
+	setImportDefaults(d, resourceSharedFlowDeployment())
+
+	if err := resourceSharedFlowDeploymentRead(d, meta); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceSharedFlowDeploymentImport error reading deployment: %s", err.Error())
+	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceSharedFlowDeploymentImport shared flow %s is not deployed to %s", name, IDEnv)
+	}
 
 	return []*schema.ResourceData{d}, nil
 }
@@ -83,14 +136,18 @@ func resourceSharedFlowDeploymentRead(d *schema.ResourceData, meta interface{})
 	log.Print("[DEBUG] resourceSharedFlowDeploymentRead START")
 	log.Printf("[DEBUG] resourceSharedFlowDeploymentRead shared_flow_name: %#v", d.Get("shared_flow_name").(string))
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	found := false
 	matchedRevision := "0"
 
 	if deployments, _, err := client.SharedFlows.GetDeployments(d.Get("shared_flow_name").(string)); err != nil {
 		log.Printf("[ERROR] resourceSharedFlowDeploymentRead error getting deployments: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceSharedFlowDeploymentRead 404 encountered.  Removing state for deployment shared_flow_name: %#v", d.Get("shared_flow_name").(string))
 			d.SetId("")
 			return nil
@@ -132,48 +189,41 @@ func resourceSharedFlowDeploymentCreate(d *schema.ResourceData, meta interface{}
 
 	log.Print("[DEBUG] resourceSharedFlowDeploymentCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentCreate %s", err.Error())
+	}
 
 	sharedFlowName := d.Get("shared_flow_name").(string)
 	env := d.Get("env").(string)
 	revInt, _ := strconv.Atoi(d.Get("revision").(string))
 	rev := apigee.Revision(revInt)
-	delay := int(d.Get("delay").(int))
-	override := bool(d.Get("override").(bool))
 
 	if d.Get("revision").(string) == "latest" {
 		// deploy latest
-		rev, err := getLatestSharedFlowRevision(client, sharedFlowName)
+		revInt, err := getLatestSharedFlowRevision(client, sharedFlowName)
 		if err != nil {
 			return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentCreate error getting latest revision: %v", err)
 		}
-		_, _, err = client.SharedFlows.Deploy(sharedFlowName, env, apigee.Revision(rev), delay, override)
-		if err != nil {
-			return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentCreate error deploying: %v", err)
-		}
-		log.Printf("[DEBUG] resourceSharedFlowDeploymentCreate Deployed revision %d of %s", rev, sharedFlowName)
-		return resourceSharedFlowDeploymentRead(d, meta)
+		rev = apigee.Revision(revInt)
 	}
 
-	sharedFlowDep, _, err := client.SharedFlows.Deploy(sharedFlowName, env, rev, delay, override)
+	if err := deployRevision(client, d, sharedFlowsPath, sharedFlowName, rev, d.Timeout(schema.TimeoutCreate)); err != nil {
+		log.Printf("[ERROR] resourceSharedFlowDeploymentCreate %s", err.Error())
+		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentCreate %s", err.Error())
+	}
 
-	if err != nil {
+	//The ID is the import ID so that created and imported deployments are alike.
+	d.SetId(fmt.Sprintf("%s_%s_deployment", sharedFlowName, env))
 
-		if strings.Contains(err.Error(), "conflicts with existing deployment path") {
-			//create, fail, update
-			log.Printf("[ERROR] resourceSharedFlowDeploymentCreate error deploying: %s", err.Error())
-			log.Print("[DEBUG] resourceSharedFlowDeploymentCreate something got out of sync... maybe someone messing around in apigee directly.  Terraform OVERRIDE!!!")
-			resourceSharedFlowDeploymentUpdate(d, meta)
-		} else {
-			log.Printf("[ERROR] resourceSharedFlowDeploymentCreate error deploying: %s", err.Error())
-			return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentCreate error deploying: %s", err.Error())
+	log.Printf("[DEBUG] resourceSharedFlowDeploymentCreate Deployed revision %d of %s", rev, sharedFlowName)
+
+	if d.Get("wait_for_ready").(bool) {
+		if err := waitForDeploymentReady(client, sharedFlowsPath, sharedFlowName, env, rev, d.Timeout(schema.TimeoutCreate)); err != nil {
+			return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentCreate %s", err.Error())
 		}
 	}
 
-	id, _ := uuid.NewV4()
-	d.SetId(id.String())
-	d.Set("revision", sharedFlowDep.Revision.String())
-
 	return resourceSharedFlowDeploymentRead(d, meta)
 }
 
@@ -181,48 +231,40 @@ func resourceSharedFlowDeploymentUpdate(d *schema.ResourceData, meta interface{}
 
 	log.Print("[DEBUG] resourceSharedFlowDeploymentUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentUpdate %s", err.Error())
+	}
+
+	if onlyDeletionProtectionChanged(d, resourceSharedFlowDeployment()) {
+		return resourceSharedFlowDeploymentRead(d, meta)
+	}
 
 	sharedFlowName := d.Get("shared_flow_name").(string)
 	env := d.Get("env").(string)
-	delay := int(d.Get("delay").(int))
-	override := bool(d.Get("override").(bool))
-
-	//We must set delay and override here if not set.
-	if delay == 0 {
-		delay = 15 //seconds
-	}
-	if override == false {
-		override = true
-	}
+	revInt, _ := strconv.Atoi(d.Get("revision").(string))
+	rev := apigee.Revision(revInt)
 
 	if d.Get("revision").(string) == "latest" {
 		// deploy latest
-		rev, err := getLatestSharedFlowRevision(client, sharedFlowName)
+		revInt, err := getLatestSharedFlowRevision(client, sharedFlowName)
 		if err != nil {
 			return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentUpdate error getting latest revision: %v", err)
 		}
-		_, _, err = client.SharedFlows.ReDeploy(sharedFlowName, env, apigee.Revision(rev), delay, override)
-		if err != nil {
-			if strings.Contains(err.Error(), " is already deployed ") {
-				return resourceSharedFlowDeploymentRead(d, meta)
-			}
-			return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentUpdate error deploying: %v", err)
-		}
-		log.Printf("[DEBUG] resourceSharedFlowDeploymentUpdate Deployed revision %d of %s", rev, sharedFlowName)
-		return resourceSharedFlowDeploymentRead(d, meta)
+		rev = apigee.Revision(revInt)
 	}
 
-	revInt, _ := strconv.Atoi(d.Get("revision").(string))
-	rev := apigee.Revision(revInt)
-	_, _, err := client.SharedFlows.ReDeploy(sharedFlowName, env, rev, delay, override)
+	if err := deployRevision(client, d, sharedFlowsPath, sharedFlowName, rev, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		log.Printf("[ERROR] resourceSharedFlowDeploymentUpdate %s", err.Error())
+		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentUpdate %s", err.Error())
+	}
 
-	if err != nil {
-		log.Printf("[ERROR] resourceSharedFlowDeploymentUpdate error redeploying: %s", err.Error())
-		if strings.Contains(err.Error(), " is already deployed into environment ") {
-			return resourceSharedFlowDeploymentRead(d, meta)
+	log.Printf("[DEBUG] resourceSharedFlowDeploymentUpdate Deployed revision %d of %s", rev, sharedFlowName)
+
+	if d.Get("wait_for_ready").(bool) {
+		if err := waitForDeploymentReady(client, sharedFlowsPath, sharedFlowName, env, rev, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentUpdate %s", err.Error())
 		}
-		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentUpdate error redeploying: %s", err.Error())
 	}
 
 	return resourceSharedFlowDeploymentRead(d, meta)
@@ -232,15 +274,22 @@ func resourceSharedFlowDeploymentDelete(d *schema.ResourceData, meta interface{}
 
 	log.Print("[DEBUG] resourceSharedFlowDeploymentDelete START")
 
-	client := meta.(*apigee.EdgeClient)
+	if err := checkDeletionProtection(d, fmt.Sprintf("deployment of shared flow %s to %s", d.Get("shared_flow_name").(string), d.Get("env").(string))); err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentDelete %s", err.Error())
+	}
+
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentDelete %s", err.Error())
+	}
 
 	sharedFlowName := d.Get("shared_flow_name").(string)
 	env := d.Get("env").(string)
 	revInt, _ := strconv.Atoi(d.Get("revision").(string))
 	rev := apigee.Revision(revInt)
 
-	_, _, err := client.SharedFlows.Undeploy(sharedFlowName, env, rev)
-	if err != nil {
+	_, _, err = client.SharedFlows.Undeploy(sharedFlowName, env, rev)
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceSharedFlowDeploymentDelete error undeploying: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceSharedFlowDeploymentDelete error undeploying: %s", err.Error())
 	}
@@ -261,3 +310,17 @@ func getLatestSharedFlowRevision(client *apigee.EdgeClient, sharedFlowName strin
 
 	return latestRevision, nil
 }
+
+func getDeployedSharedFlowRevision(client *apigee.EdgeClient, sharedFlowName string, env string) (int, error) {
+	deployments, _, err := client.SharedFlows.GetDeployments(sharedFlowName)
+	if err != nil {
+		return -1, fmt.Errorf("[ERROR] getDeployedSharedFlowRevision error reading deployments: %s", err.Error())
+	}
+
+	rev, found := lastDeployedRevision(deployments.Environments, env)
+	if !found {
+		return -1, fmt.Errorf("[ERROR] getDeployedSharedFlowRevision no revision of %s is deployed to %s", sharedFlowName, env)
+	}
+
+	return int(rev), nil
+}
@@ -0,0 +1,79 @@
+package apigee
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceSharedFlowDeploymentV0 is the schema of apigee_shared_flow_deployment while org was required and ignored.
+func resourceSharedFlowDeploymentV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"shared_flow_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"org": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"env": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"revision": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"delay": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"override": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+// resourceSharedFlowDeploymentStateUpgradeV0 drops the org of the state, the shared flow was deployed in the
+// provider's organization whatever org said.  The next read stores the organization it is deployed in.
+func resourceSharedFlowDeploymentStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceSharedFlowDeploymentStateUpgradeV0 START")
+
+	delete(rawState, "org")
+
+	return rawState, nil
+}
+
+// resourceSharedFlowDeploymentV1 is the schema of apigee_shared_flow_deployment while it was keyed by a UUID.
+func resourceSharedFlowDeploymentV1() *schema.Resource {
+
+	r := resourceSharedFlowDeploymentV0()
+	r.Schema["org"] = &schema.Schema{Type: schema.TypeString, Optional: true, Computed: true, ForceNew: true}
+
+	return r
+}
+
+// resourceSharedFlowDeploymentStateUpgradeV1 keys the deployment by {shared_flow_name}_{env}_deployment, as
+// an import does, instead of the UUID it was created with.
+func resourceSharedFlowDeploymentStateUpgradeV1(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceSharedFlowDeploymentStateUpgradeV1 START")
+
+	name, _ := rawState["shared_flow_name"].(string)
+	env, _ := rawState["env"].(string)
+	if name != "" && env != "" {
+		rawState["id"] = name + "_" + env + "_deployment"
+	}
+
+	return rawState, nil
+}
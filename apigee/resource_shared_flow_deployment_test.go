@@ -3,7 +3,6 @@ package apigee
 import (
 	"fmt"
 	"log"
-	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -44,6 +43,32 @@ func TestAccSharedFlowDeployment_Updated(t *testing.T) {
 						"apigee_shared_flow_deployment.foo_shared_flow_deployment", "delay", "2"),
 					resource.TestCheckResourceAttr(
 						"apigee_shared_flow_deployment.foo_shared_flow_deployment", "override", "true"),
+					resource.TestCheckResourceAttr(
+						"apigee_shared_flow_deployment.foo_shared_flow_deployment", "wait_for_ready", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestSharedFlowDeploymentSeamless(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fakeEdgeProvider(server, "")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + fmt.Sprintf(testSharedFlowDeploymentSeamlessConfig, "helloworld_shared_flow.zip", "1"),
+				Check:  testCheckFakeDeployed(fake, "sharedflows/foo_shared_flow_terraformed", "test", 1, 1),
+			},
+			{
+				Config: provider + fmt.Sprintf(testSharedFlowDeploymentSeamlessConfig, "helloworld_shared_flow2.zip", "2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("apigee_shared_flow_deployment.foo", "revision", "2"),
+					resource.TestCheckResourceAttr("apigee_shared_flow_deployment.foo", "previous_revision", "1"),
+					testCheckFakeDeployed(fake, "sharedflows/foo_shared_flow_terraformed", "test", 2, 2),
 				),
 			},
 		},
@@ -52,7 +77,7 @@ func TestAccSharedFlowDeployment_Updated(t *testing.T) {
 
 func testAccCheckSharedFlowDeploymentDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := sharedFlowDeploymentDestroyHelper(s, client); err != nil {
 		return err
@@ -62,7 +87,7 @@ func testAccCheckSharedFlowDeploymentDestroy(s *terraform.State) error {
 
 func testAccCheckSharedFlowDeploymentExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := sharedFlowDeploymentExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckSharedFlowDeploymentExists: %s", err)
 			return err
@@ -101,6 +126,7 @@ resource "apigee_shared_flow_deployment" "foo_shared_flow_deployment" {
    revision     = "2"
    delay		= "2"
    override 	= true
+   wait_for_ready = true
 }
 `
 
@@ -116,7 +142,7 @@ func sharedFlowDeploymentDestroyHelper(s *terraform.State, client *apigee.EdgeCl
 		_, _, err := client.SharedFlows.GetDeployments("foo_shared_flow_deployment")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving shared flow deployment  %+v\n", err)
@@ -144,3 +170,79 @@ func sharedFlowDeploymentExistsHelper(s *terraform.State, client *apigee.EdgeCli
 	}
 	return nil
 }
+
+func TestSharedFlowDeploymentImport(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testSharedFlowDeploymentImportConfig
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:            config,
+				ResourceName:      "apigee_shared_flow_deployment.foo_shared_flow_deployment",
+				ImportState:       true,
+				ImportStateId:     "foo_shared_flow_terraformed_test_deployment",
+				ImportStateVerify: true,
+				// Apigee does not tell which revision was deployed before.
+				ImportStateVerifyIgnore: []string{"previous_revision"},
+			},
+		},
+	})
+}
+
+const testSharedFlowDeploymentImportConfig = `
+resource "apigee_shared_flow" "foo_shared_flow" {
+   name         = "foo_shared_flow_terraformed"
+   bundle       = "test-fixtures/helloworld_shared_flow.zip"
+   bundle_sha   = filebase64sha256("test-fixtures/helloworld_shared_flow.zip")
+}
+
+resource "apigee_shared_flow_deployment" "foo_shared_flow_deployment" {
+   shared_flow_name   = apigee_shared_flow.foo_shared_flow.name
+   env                = "test"
+   revision           = apigee_shared_flow.foo_shared_flow.revision
+}
+`
+
+const testSharedFlowDeploymentSeamlessConfig = `
+resource "apigee_shared_flow" "foo" {
+   name         = "foo_shared_flow_terraformed"
+   bundle       = "test-fixtures/%[1]s"
+   bundle_sha   = filebase64sha256("test-fixtures/%[1]s")
+}
+
+resource "apigee_shared_flow_deployment" "foo" {
+   shared_flow_name   = apigee_shared_flow.foo.name
+   env                = "test"
+   revision           = "%[2]s"
+   strategy           = "seamless"
+   delay              = 5
+}
+`
+
+func TestResourceSharedFlowDeploymentStateUpgrade(t *testing.T) {
+
+	state, err := resourceSharedFlowDeploymentStateUpgradeV0(map[string]interface{}{
+		"id":               "6f1c2b3a-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		"shared_flow_name": "common",
+		"org":              "whatever-org",
+		"env":              "test",
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if state, err = resourceSharedFlowDeploymentStateUpgradeV1(state, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := state["org"]; ok {
+		t.Fatalf("expected the ignored org to be dropped, got %v", state["org"])
+	}
+	if state["id"] != "common_test_deployment" {
+		t.Fatalf("expected the ID to be common_test_deployment, got %v", state["id"])
+	}
+}
@@ -0,0 +1,49 @@
+package apigee
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceSharedFlowV0 is the schema of apigee_shared_flow while it was keyed by a random UUID.
+func resourceSharedFlowV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"bundle": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"bundle_sha": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			//revision_sha is used as a workaround for: https://github.com/hashicorp/terraform/issues/15857
+			"revision_sha": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"revision": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// resourceSharedFlowStateUpgradeV0 keys the shared flow by its name instead of a UUID, as an import of it does.
+func resourceSharedFlowStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceSharedFlowStateUpgradeV0 START")
+
+	if name, ok := rawState["name"].(string); ok && name != "" {
+		rawState["id"] = name
+	}
+
+	return rawState, nil
+}
@@ -3,7 +3,6 @@ package apigee
 import (
 	"fmt"
 	"log"
-	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -47,7 +46,7 @@ func TestAccSharedFlow_Updated(t *testing.T) {
 
 func testAccCheckSharedFlowDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := sharedFlowDestroyHelper(s, client); err != nil {
 		return err
@@ -57,7 +56,7 @@ func testAccCheckSharedFlowDestroy(s *terraform.State) error {
 
 func testAccCheckSharedFlowExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := sharedFlowExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckSharedFlowExists: %s", err)
 			return err
@@ -94,7 +93,7 @@ func sharedFlowDestroyHelper(s *terraform.State, client *apigee.EdgeClient) erro
 		_, _, err := client.SharedFlows.Get("foo_shared_flow")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving shared flow  %+v\n", err)
@@ -122,3 +121,41 @@ func sharedFlowExistsHelper(s *terraform.State, client *apigee.EdgeClient, name
 	}
 	return nil
 }
+
+func TestSharedFlowImport(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testAccCheckSharedFlowConfigRequired
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:            config,
+				ResourceName:      "apigee_shared_flow.foo_shared_flow",
+				ImportState:       true,
+				ImportStateId:     "foo_shared_flow_terraformed",
+				ImportStateVerify: true,
+				// The bundle is not read back.
+				ImportStateVerifyIgnore: []string{"bundle", "bundle_sha", "revision_sha"},
+			},
+		},
+	})
+}
+
+func TestResourceSharedFlowStateUpgradeV0(t *testing.T) {
+
+	actual, err := resourceSharedFlowStateUpgradeV0(map[string]interface{}{
+		"id":   "6f1c2b3a-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		"name": "common",
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual["id"] != "common" {
+		t.Fatalf("expected the ID to be the shared flow name, got %v", actual["id"])
+	}
+}
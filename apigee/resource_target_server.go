@@ -5,8 +5,8 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/gofrs/uuid"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/zambien/go-apigee-edge"
 )
@@ -21,7 +21,28 @@ func resourceTargetServer() *schema.Resource {
 			State: resourceTargetServerImport,
 		},
 
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceTargetServerV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceTargetServerStateUpgradeV0,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -96,10 +117,13 @@ func resourceTargetServerCreate(d *schema.ResourceData, meta interface{}) error
 
 	log.Print("[DEBUG] resourceTargetServerCreate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceTargetServerCreate %s", err.Error())
+	}
 
-	u1, _ := uuid.NewV4()
-	d.SetId(u1.String())
+	//The ID is the import ID so that created and imported target servers are alike.
+	d.SetId(fmt.Sprintf("%s_%s", d.Get("name").(string), d.Get("env").(string)))
 
 	targetServerData, err := setTargetServerData(d)
 	if err != nil {
@@ -119,43 +143,26 @@ func resourceTargetServerCreate(d *schema.ResourceData, meta interface{}) error
 func resourceTargetServerImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 
 	log.Print("[DEBUG] resourceTargetServerImport START")
-	client := meta.(*apigee.EdgeClient)
+
+	importOrg(d)
+
 	splits := strings.Split(d.Id(), "_")
-	if len(splits) < 1 {
+	if len(splits) < 2 {
 		return []*schema.ResourceData{}, fmt.Errorf("[ERR] Wrong format of resource: %s. Please follow '{name}_{env}'", d.Id())
 	}
 
-	nameOffset := len(splits[len(splits)-1])
-	name := d.Id()[:(len(d.Id())-nameOffset)-1]
 	IDEnv := splits[len(splits)-1]
+	name := strings.TrimSuffix(d.Id(), "_"+IDEnv)
 
-	targetServerData, _, err := client.TargetServers.Get(name, IDEnv)
-	if err != nil {
-		log.Printf("[ERROR] resourceTargetServerImport error getting target servers: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
-			return []*schema.ResourceData{}, fmt.Errorf("[Error] resourceTargetServerImport 404 encountered.  Removing state for target server: %#v", name)
-		}
-		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceTargetServerImport error getting target servers: %s", err.Error())
-	}
-
-	d.Set("name", targetServerData.Name)
-	d.Set("host", targetServerData.Host)
-	d.Set("enabled", targetServerData.Enabled)
-	d.Set("port", targetServerData.Port)
+	d.Set("name", name)
 	d.Set("env", IDEnv)
+	setImportDefaults(d, resourceTargetServer())
 
-	if targetServerData.SSLInfo != nil {
-		protocols := flattenStringList(targetServerData.SSLInfo.Protocols)
-		ciphers := flattenStringList(targetServerData.SSLInfo.Ciphers)
-
-		d.Set("ssl_info.0.ssl_enabled", targetServerData.SSLInfo.SSLEnabled)
-		d.Set("ssl_info.0.client_auth_enabled", targetServerData.SSLInfo.ClientAuthEnabled)
-		d.Set("ssl_info.0.key_store", targetServerData.SSLInfo.KeyStore)
-		d.Set("ssl_info.0.trust_store", targetServerData.SSLInfo.TrustStore)
-		d.Set("ssl_info.0.key_alias", targetServerData.SSLInfo.KeyAlias)
-		d.Set("ssl_info.0.ciphers", ciphers)
-		d.Set("ssl_info.0.ignore_validation_errors", targetServerData.SSLInfo.IgnoreValidationErrors)
-		d.Set("ssl_info.0.protocols", protocols)
+	if err := resourceTargetServerRead(d, meta); err != nil {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceTargetServerImport error reading target server: %s", err.Error())
+	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] resourceTargetServerImport target server %s does not exist in %s", name, IDEnv)
 	}
 
 	return []*schema.ResourceData{d}, nil
@@ -164,12 +171,16 @@ func resourceTargetServerImport(d *schema.ResourceData, meta interface{}) ([]*sc
 func resourceTargetServerRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceTargetServerRead START")
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceTargetServerRead %s", err.Error())
+	}
+	d.Set("org", orgName(d, meta))
 
 	targetServerData, _, err := client.TargetServers.Get(d.Get("name").(string), d.Get("env").(string))
 	if err != nil {
 		log.Printf("[ERROR] resourceTargetServerRead error getting target servers: %s", err.Error())
-		if strings.Contains(err.Error(), "404 ") {
+		if isNotFound(err) {
 			log.Printf("[DEBUG] resourceTargetServerRead 404 encountered.  Removing state for target server: %#v", d.Get("name").(string))
 			d.SetId("")
 			return nil
@@ -185,19 +196,7 @@ func resourceTargetServerRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("enabled", targetServerData.Enabled)
 	d.Set("port", port_str)
 
-	if targetServerData.SSLInfo != nil {
-		protocols := flattenStringList(targetServerData.SSLInfo.Protocols)
-		ciphers := flattenStringList(targetServerData.SSLInfo.Ciphers)
-
-		d.Set("ssl_info.0.ssl_enabled", targetServerData.SSLInfo.SSLEnabled)
-		d.Set("ssl_info.0.client_auth_enabled", targetServerData.SSLInfo.ClientAuthEnabled)
-		d.Set("ssl_info.0.key_store", targetServerData.SSLInfo.KeyStore)
-		d.Set("ssl_info.0.trust_store", targetServerData.SSLInfo.TrustStore)
-		d.Set("ssl_info.0.key_alias", targetServerData.SSLInfo.KeyAlias)
-		d.Set("ssl_info.0.ciphers", ciphers)
-		d.Set("ssl_info.0.ignore_validation_errors", targetServerData.SSLInfo.IgnoreValidationErrors)
-		d.Set("ssl_info.0.protocols", protocols)
-	}
+	d.Set("ssl_info", flattenSSLInfo(targetServerData.SSLInfo))
 
 	return nil
 }
@@ -206,7 +205,10 @@ func resourceTargetServerUpdate(d *schema.ResourceData, meta interface{}) error
 
 	log.Print("[DEBUG] resourceTargetServerUpdate START")
 
-	client := meta.(*apigee.EdgeClient)
+	client, err := orgClient(d, meta)
+	if err != nil {
+		return fmt.Errorf("[ERROR] resourceTargetServerUpdate %s", err.Error())
+	}
 
 	targetServerData, err := setTargetServerData(d)
 	if err != nil {
@@ -226,10 +228,13 @@ func resourceTargetServerUpdate(d *schema.ResourceData, meta interface{}) error
 func resourceTargetServerDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Print("[DEBUG] resourceTargetServerDelete START")
-	client := meta.(*apigee.EdgeClient)
-
-	_, err := client.TargetServers.Delete(d.Get("name").(string), d.Get("env").(string))
+	client, err := orgClient(d, meta)
 	if err != nil {
+		return fmt.Errorf("[ERROR] resourceTargetServerDelete %s", err.Error())
+	}
+
+	_, err = client.TargetServers.Delete(d.Get("name").(string), d.Get("env").(string))
+	if err != nil && !isNotFound(err) {
 		log.Printf("[ERROR] resourceTargetServerDelete error in delete: %s", err.Error())
 		return fmt.Errorf("[ERROR] resourceTargetServerDelete error in delete: %s", err.Error())
 	}
@@ -244,7 +249,7 @@ func setTargetServerData(d *schema.ResourceData) (apigee.TargetServer, error) {
 	port_int, _ := strconv.Atoi(d.Get("port").(string))
 
 	var ssl_info *apigee.SSLInfo
-	if d.Get("ssl_info") != nil {
+	if len(d.Get("ssl_info").([]interface{})) > 0 {
 		ciphers := []string{""}
 		if d.Get("ssl_info.0.ciphers") != nil {
 			ciphers = getStringList("ssl_info.0.ciphers", d)
@@ -278,3 +283,22 @@ func setTargetServerData(d *schema.ResourceData) (apigee.TargetServer, error) {
 
 	return targetServer, nil
 }
+
+// flattenSSLInfo returns the ssl_info block of a target server, none when it has no SSL settings.
+func flattenSSLInfo(sslInfo *apigee.SSLInfo) []interface{} {
+
+	if sslInfo == nil || sslInfo.SSLEnabled == "" {
+		return []interface{}{}
+	}
+
+	return []interface{}{map[string]interface{}{
+		"ssl_enabled":              sslInfo.SSLEnabled,
+		"client_auth_enabled":      sslInfo.ClientAuthEnabled,
+		"key_store":                sslInfo.KeyStore,
+		"trust_store":              sslInfo.TrustStore,
+		"key_alias":                sslInfo.KeyAlias,
+		"ciphers":                  flattenStringList(sslInfo.Ciphers),
+		"ignore_validation_errors": sslInfo.IgnoreValidationErrors,
+		"protocols":                flattenStringList(sslInfo.Protocols),
+	}}
+}
@@ -0,0 +1,96 @@
+package apigee
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceTargetServerV0 is the schema of apigee_target_server while it was keyed by the UUID it was created with.
+func resourceTargetServerV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"host": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: false,
+			},
+			"env": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Required: true,
+			},
+			"port": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"ssl_info": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ssl_enabled": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"client_auth_enabled": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"key_store": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"trust_store": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"key_alias": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"ciphers": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"ignore_validation_errors": &schema.Schema{
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+						"protocols": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// resourceTargetServerStateUpgradeV0 moves the ID from a UUID to {name}_{env}, the ID target servers are created and
+// imported with.
+func resourceTargetServerStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	log.Print("[DEBUG] resourceTargetServerStateUpgradeV0 START")
+
+	name, _ := rawState["name"].(string)
+	env, _ := rawState["env"].(string)
+	if name != "" && env != "" {
+		rawState["id"] = name + "_" + env
+	}
+
+	return rawState, nil
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/zambien/go-apigee-edge"
 	"log"
-	"strings"
 	"testing"
 )
 
@@ -104,7 +103,7 @@ func TestAccTargetServer_CreateWithoutSSLInfo(t *testing.T) {
 
 func testAccCheckTargetServerDestroy(s *terraform.State) error {
 
-	client := testAccProvider.Meta().(*apigee.EdgeClient)
+	client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 
 	if err := targetServerDestroyHelper(s, client); err != nil {
 		return err
@@ -114,7 +113,7 @@ func testAccCheckTargetServerDestroy(s *terraform.State) error {
 
 func testAccCheckTargetServerExists(n string, name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*apigee.EdgeClient)
+		client := testAccProvider.Meta().(*apigeeClients).defaultClient()
 		if err := targetServerExistsHelper(s, client, name); err != nil {
 			log.Printf("Error in testAccCheckTargetServerExists: %s", err)
 			return err
@@ -182,7 +181,7 @@ func targetServerDestroyHelper(s *terraform.State, client *apigee.EdgeClient) er
 		_, _, err := client.TargetServers.Get("foo_target_server", "test")
 
 		if err != nil {
-			if strings.Contains(err.Error(), "404 ") {
+			if isNotFound(err) {
 				return nil
 			}
 			return fmt.Errorf("Received an error retrieving target server  %+v\n", err)
@@ -210,3 +209,82 @@ func targetServerExistsHelper(s *terraform.State, client *apigee.EdgeClient, nam
 	}
 	return nil
 }
+
+func TestTargetServerImport(t *testing.T) {
+
+	_, server := newFakeEdge(t)
+	config := fakeEdgeProvider(server, "") + testAccCheckTargetServerConfigUpdated
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("apigee_target_server.foo", "org", "test-org"),
+			},
+			{
+				Config:            config,
+				ResourceName:      "apigee_target_server.foo",
+				ImportState:       true,
+				ImportStateId:     "foo_target_server_updated_test",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestTargetServerImportOtherOrg(t *testing.T) {
+
+	fake, server := newFakeEdge(t)
+	provider := fmt.Sprintf(`
+provider "apigee" {
+   base_uri     = "%s"
+   org          = "prod-org"
+   access_token = "token"
+   max_retries  = 0
+}
+`, server.URL)
+	config := provider + `
+resource "apigee_target_server" "foo" {
+  org = "test-org"
+  name = "foo"
+  host = "some.api.com"
+  env = "test"
+  port = 443
+}
+`
+	fake.seed("environments/test/targetservers/foo", `{"name":"foo","host":"some.api.com","port":443,"isEnabled":true}`)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:        config,
+				ResourceName:  "apigee_target_server.foo",
+				ImportState:   true,
+				ImportStateId: "test-org/foo_test",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].ID != "foo_test" || states[0].Attributes["org"] != "test-org" {
+						return fmt.Errorf("expected foo_test of test-org to be imported, got %v", states)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestResourceTargetServerStateUpgradeV0(t *testing.T) {
+
+	actual, err := resourceTargetServerStateUpgradeV0(map[string]interface{}{
+		"id":   "6f1c2b3a-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+		"name": "backend",
+		"env":  "test",
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual["id"] != "backend_test" {
+		t.Fatalf("expected the ID to be backend_test, got %v", actual["id"])
+	}
+}
@@ -0,0 +1,158 @@
+package apigee
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxRetries   = 5
+	defaultRetryMinWait = 1 * time.Second
+	defaultRetryMaxWait = 30 * time.Second
+
+	// retryBaseWait is the first wait when retry_min_wait is 0, the backoff has to start from something to double.
+	retryBaseWait = 100 * time.Millisecond
+)
+
+// retryTransport retries requests that failed for transient reasons, waiting with jittered exponential backoff or
+// for as long as the server asks with Retry-After.
+type retryTransport struct {
+	maxRetries int
+	minWait    time.Duration
+	maxWait    time.Duration
+	base       http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			log.Printf("[DEBUG] retryTransport %s %s returned %d, retrying in %s", req.Method, req.URL.Path, resp.StatusCode, wait)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			log.Printf("[DEBUG] retryTransport %s %s failed: %s, retrying in %s", req.Method, req.URL.Path, err.Error(), wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether a failed request is safe and worth sending again.  Only idempotent requests are
+// retried after a server error or a dropped connection since the first attempt may have been applied.  A 429 means
+// the request was turned away unprocessed so any request is retried.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return isIdempotent(req.Method) && isConnectionError(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req.Method)
+	}
+
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+func isConnectionError(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff returns how long to wait before the next attempt.  Retry-After wins when the server sends it, up to the
+// maximum wait.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if wait > t.maxWait {
+				return t.maxWait
+			}
+			return wait
+		}
+	}
+
+	minWait := t.minWait
+	if minWait <= 0 {
+		minWait = retryBaseWait
+	}
+	wait := t.maxWait
+	if attempt < 32 {
+		if exp := minWait << uint(attempt); exp > 0 && exp < t.maxWait {
+			wait = exp
+		}
+	}
+
+	// Equal jitter, half of the wait is fixed and half random, keeps parallel resources from retrying in lockstep.
+	if half := int64(wait / 2); half > 0 {
+		wait = time.Duration(half + rand.Int63n(half+1))
+	}
+
+	return wait
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
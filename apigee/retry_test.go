@@ -0,0 +1,212 @@
+package apigee
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/zambien/go-apigee-edge"
+)
+
+// flakyServer fails the first requests to every path with the given status before answering normally.
+type flakyServer struct {
+	mu         sync.Mutex
+	failures   int
+	status     int
+	retryAfter string
+	hangUp     bool
+	requests   map[string]int
+}
+
+func (f *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	f.mu.Lock()
+	key := r.Method + " " + r.URL.Path
+	f.requests[key]++
+	fail := f.requests[key] <= f.failures
+	f.mu.Unlock()
+
+	if fail && f.hangUp {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	if fail {
+		if f.retryAfter != "" {
+			w.Header().Set("Retry-After", f.retryAfter)
+		}
+		w.WriteHeader(f.status)
+		return
+	}
+
+	fmt.Fprint(w, `{"name":"helloworld","email":"someone@example.com"}`)
+}
+
+func newFlakyClient(t *testing.T, server *httptest.Server, maxRetries int) *apigee.EdgeClient {
+
+	config := Config{
+		BaseURI:      server.URL,
+		Org:          "test-org",
+		AccessToken:  "token",
+		MaxRetries:   maxRetries,
+		RetryMinWait: time.Millisecond,
+		RetryMaxWait: 10 * time.Millisecond,
+	}
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return client
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		flaky := &flakyServer{failures: 2, status: status, requests: map[string]int{}}
+		server := httptest.NewServer(flaky)
+
+		if _, _, err := newFlakyClient(t, server, 3).Proxies.Get("helloworld"); err != nil {
+			t.Errorf("%d: expected the request to succeed after retrying, got: %s", status, err)
+		}
+		if requests := flaky.requests["GET /v1/o/test-org/apis/helloworld"]; requests != 3 {
+			t.Errorf("%d: expected 3 requests, got %d", status, requests)
+		}
+
+		server.Close()
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+
+	flaky := &flakyServer{failures: 10, status: http.StatusServiceUnavailable, requests: map[string]int{}}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	if _, _, err := newFlakyClient(t, server, 2).Proxies.Get("helloworld"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if requests := flaky.requests["GET /v1/o/test-org/apis/helloworld"]; requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestRetryOnlyIdempotentServerErrors(t *testing.T) {
+
+	flaky := &flakyServer{failures: 1, status: http.StatusServiceUnavailable, requests: map[string]int{}}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+	client := newFlakyClient(t, server, 3)
+
+	if _, _, err := client.Developers.Create(apigee.Developer{Email: "someone@example.com"}); err == nil {
+		t.Fatal("expected the POST not to be retried")
+	}
+	if requests := flaky.requests["POST /v1/o/test-org/developers"]; requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+
+	if _, _, err := client.Developers.Update(apigee.Developer{Email: "someone@example.com"}); err != nil {
+		t.Fatalf("expected the PUT to be retried, got: %s", err)
+	}
+	if requests := flaky.requests["PUT /v1/o/test-org/developers/someone@example.com"]; requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestRetryRateLimitedPost(t *testing.T) {
+
+	flaky := &flakyServer{failures: 1, status: http.StatusTooManyRequests, retryAfter: "0", requests: map[string]int{}}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	if _, _, err := newFlakyClient(t, server, 3).Developers.Create(apigee.Developer{Email: "someone@example.com"}); err != nil {
+		t.Fatalf("expected the rate limited POST to be retried, got: %s", err)
+	}
+	if requests := flaky.requests["POST /v1/o/test-org/developers"]; requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestRetryConnectionReset(t *testing.T) {
+
+	flaky := &flakyServer{failures: 1, hangUp: true, requests: map[string]int{}}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	if _, _, err := newFlakyClient(t, server, 3).Proxies.Get("helloworld"); err != nil {
+		t.Fatalf("expected the request to succeed after retrying, got: %s", err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+
+	transport := &retryTransport{minWait: time.Second, maxWait: 8 * time.Second}
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second} {
+		wait := transport.backoff(attempt, nil)
+		if wait < max/2 || wait > max {
+			t.Errorf("attempt %d: expected a wait between %s and %s, got %s", attempt, max/2, max, wait)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"5"}}}
+	if wait := transport.backoff(0, resp); wait != 5*time.Second {
+		t.Errorf("expected Retry-After to be honored, got %s", wait)
+	}
+	resp = &http.Response{Header: http.Header{"Retry-After": {"42"}}}
+	if wait := transport.backoff(0, resp); wait != 8*time.Second {
+		t.Errorf("expected Retry-After to be capped at the maximum wait, got %s", wait)
+	}
+
+	// Without a minimum the backoff starts small rather than at the maximum.
+	transport = &retryTransport{minWait: 0, maxWait: 8 * time.Second}
+	for attempt, max := range []time.Duration{retryBaseWait, 2 * retryBaseWait, 4 * retryBaseWait} {
+		wait := transport.backoff(attempt, nil)
+		if wait < max/2 || wait > max {
+			t.Errorf("attempt %d without a minimum: expected a wait between %s and %s, got %s", attempt, max/2, max, wait)
+		}
+	}
+}
+
+func TestRetryWaitsValidated(t *testing.T) {
+
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"org":            "test-org",
+		"access_token":   "token",
+		"retry_min_wait": 10,
+		"retry_max_wait": 5,
+	}))
+	expected := regexp.MustCompile(`retry_max_wait \(5s\) must not be less than retry_min_wait \(10s\)`)
+	if err == nil || !expected.MatchString(err.Error()) {
+		t.Fatalf("expected retry_max_wait below retry_min_wait to be rejected, got: %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		header string
+		wait   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"Wed, 01 Jan 2020 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 Jan 2020 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, c := range cases {
+		wait, ok := retryAfter(c.header, now)
+		if wait != c.wait || ok != c.ok {
+			t.Errorf("retryAfter(%q) = %s, %t, expected %s, %t", c.header, wait, ok, c.wait, c.ok)
+		}
+	}
+}
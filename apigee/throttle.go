@@ -0,0 +1,108 @@
+package apigee
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// throttleTransport spaces requests out to at most requestsPerSecond and keeps at most maxConcurrent of them in
+// flight.  Every client of the provider is built on the same transport chain (see Config.transport) so this holds
+// across Terraform's parallel operations and organizations.
+type throttleTransport struct {
+	interval time.Duration
+	slots    chan struct{}
+	base     http.RoundTripper
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newThrottleTransport(requestsPerSecond float64, maxConcurrent int, base http.RoundTripper) *throttleTransport {
+
+	t := &throttleTransport{base: base}
+	if requestsPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	return t
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if err := t.wait(req); err != nil {
+		t.release()
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+
+	// The request is in flight until its response has been read.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.release}
+	return resp, nil
+}
+
+// wait blocks until the request's turn under the rate limit.
+func (t *throttleTransport) wait(req *http.Request) error {
+
+	if t.interval == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	turn := t.next
+	if turn.Before(now) {
+		turn = now
+	}
+	t.next = turn.Add(t.interval)
+	t.mu.Unlock()
+
+	delay := turn.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+func (t *throttleTransport) release() {
+	if t.slots != nil {
+		<-t.slots
+	}
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
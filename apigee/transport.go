@@ -0,0 +1,99 @@
+package apigee
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/sethgrid/pester"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newPesterClient returns the http client a go-apigee-edge client sends its requests through, by way of transport.
+// Retries are up to retryTransport, which unlike pester leaves non idempotent requests alone.
+func newPesterClient(transport http.RoundTripper) *pester.Client {
+
+	client := pester.New()
+	client.Transport = transport
+	client.MaxRetries = 1
+
+	return client
+}
+
+// baseTransport is the transport the provider starts from, a copy of http.DefaultTransport
+// so that settings made for one client never leak into another.
+func baseTransport() *http.Transport {
+	return http.DefaultTransport.(*http.Transport).Clone()
+}
+
+// httpTransport is the transport every request ends up in, with the provider's TLS and proxy settings applied.
+func (c *Config) httpTransport() (*http.Transport, error) {
+
+	transport := baseTransport()
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	if c.CACertFile != "" {
+		pem, err := ioutil.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] httpTransport error reading ca_cert_file: %s", err.Error())
+		}
+		// The CA is trusted on top of the system's so that other endpoints, login.apigee.com for one, keep working.
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("[ERROR] httpTransport no PEM certificates found in ca_cert_file %s", c.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		if c.ClientCertFile == "" || c.ClientKeyFile == "" {
+			return nil, fmt.Errorf("[ERROR] httpTransport client_cert_file and client_key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] httpTransport error loading the client certificate: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	if c.HTTPProxy != "" || c.NoProxy != "" {
+		proxy, err := c.proxy()
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = proxy
+	}
+
+	return transport, nil
+}
+
+// proxy picks the proxy of a request.  http_proxy is used for http and https alike, without it the usual
+// environment variables are, and no_proxy overrides NO_PROXY either way.
+func (c *Config) proxy() (func(*http.Request) (*url.URL, error), error) {
+
+	proxyConfig := httpproxy.FromEnvironment()
+	if c.HTTPProxy != "" {
+		if _, err := url.Parse(c.HTTPProxy); err != nil {
+			return nil, fmt.Errorf("[ERROR] proxy invalid http_proxy %s: %s", c.HTTPProxy, err.Error())
+		}
+		proxyConfig.HTTPProxy = c.HTTPProxy
+		proxyConfig.HTTPSProxy = c.HTTPProxy
+	}
+	if c.NoProxy != "" {
+		proxyConfig.NoProxy = c.NoProxy
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
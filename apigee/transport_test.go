@@ -0,0 +1,192 @@
+package apigee
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTLSTestServer(t *testing.T, clientCAs *x509.CertPool) *httptest.Server {
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"helloworld"}`)
+	}))
+	if clientCAs != nil {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// writePEM writes PEM blocks of the given type to a file in a temporary directory and returns its path.
+func writePEM(t *testing.T, name string, blockType string, der []byte) string {
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return path
+}
+
+func getHelloworld(config Config) error {
+
+	client, err := config.Client()
+	if err != nil {
+		return err
+	}
+	_, _, err = client.Proxies.Get("helloworld")
+
+	return err
+}
+
+func TestTransportCACertFile(t *testing.T) {
+
+	server := newTLSTestServer(t, nil)
+	config := Config{BaseURI: server.URL, Org: "test-org", AccessToken: "token"}
+
+	if err := getHelloworld(config); err == nil {
+		t.Fatal("expected the server's certificate not to be trusted")
+	}
+
+	config.CACertFile = writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	if err := getHelloworld(config); err != nil {
+		t.Fatalf("expected the server's certificate to be trusted, got: %s", err)
+	}
+
+	config.CACertFile = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := config.Client(); err == nil {
+		t.Fatal("expected an error for a missing ca_cert_file")
+	}
+}
+
+func TestTransportEveryOrganization(t *testing.T) {
+
+	server := newTLSTestServer(t, nil)
+	config := Config{
+		BaseURI:               server.URL,
+		Org:                   "test-org",
+		AccessToken:           "token",
+		CACertFile:            writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw),
+		MaxConcurrentRequests: 1,
+	}
+	clients, err := config.Clients()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Clients made later for other organizations go through the same transports, TLS settings included.
+	other, err := clients.client("other-org")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, _, err := other.Proxies.Get("helloworld"); err != nil {
+		t.Fatalf("expected the server's certificate to be trusted, got: %s", err)
+	}
+	if _, ok := clients.transport.(*errorBodyTransport); !ok {
+		t.Fatalf("expected the transport chain to end in errorBodyTransport, got %T", clients.transport)
+	}
+}
+
+func TestTransportInsecureSkipVerify(t *testing.T) {
+
+	server := newTLSTestServer(t, nil)
+	config := Config{BaseURI: server.URL, Org: "test-org", AccessToken: "token", InsecureSkipVerify: true}
+
+	if err := getHelloworld(config); err != nil {
+		t.Fatalf("expected the certificate not to be verified, got: %s", err)
+	}
+}
+
+func TestTransportClientCertificate(t *testing.T) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	cert, _ := x509.ParseCertificate(certDER)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	server := newTLSTestServer(t, clientCAs)
+	config := Config{
+		BaseURI:     server.URL,
+		Org:         "test-org",
+		AccessToken: "token",
+		CACertFile:  writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw),
+	}
+
+	if err := getHelloworld(config); err == nil {
+		t.Fatal("expected the server to ask for a client certificate")
+	}
+
+	config.ClientCertFile = writePEM(t, "client.pem", "CERTIFICATE", certDER)
+	if _, err := config.Client(); err == nil {
+		t.Fatal("expected an error for client_cert_file without client_key_file")
+	}
+
+	config.ClientKeyFile = writePEM(t, "client-key.pem", "EC PRIVATE KEY", keyDER)
+	if err := getHelloworld(config); err != nil {
+		t.Fatalf("expected the client certificate to be accepted, got: %s", err)
+	}
+}
+
+func TestTransportHTTPProxy(t *testing.T) {
+
+	proxied := ""
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		fmt.Fprint(w, `{"name":"helloworld"}`)
+	}))
+	defer proxy.Close()
+
+	config := Config{BaseURI: "http://apigee.example.com", Org: "test-org", AccessToken: "token", HTTPProxy: proxy.URL}
+	if err := getHelloworld(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if proxied != "http://apigee.example.com/v1/o/test-org/apis/helloworld" {
+		t.Fatalf("expected the request to go through the proxy, got %q", proxied)
+	}
+
+	config.NoProxy = "other.example.com,apigee.example.com"
+	transport, err := config.httpTransport()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	req, _ := http.NewRequest("GET", "https://apigee.example.com/v1/o/test-org/apis", nil)
+	if proxyURL, err := transport.Proxy(req); err != nil || proxyURL != nil {
+		t.Fatalf("expected no_proxy hosts to be reached directly, got %v, %v", proxyURL, err)
+	}
+}
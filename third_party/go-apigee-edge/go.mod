@@ -0,0 +1,9 @@
+module github.com/zambien/go-apigee-edge
+
+go 1.13
+
+require (
+	github.com/bgentry/go-netrc v0.0.0-20140422174119-9fd32a8b3d3d
+	github.com/google/go-querystring v1.0.0
+	github.com/sethgrid/pester v0.0.0-20190127155807-68a33a018ad0
+)